@@ -18,7 +18,11 @@ func NewBatch() *Batch {
 }
 
 func (batch *Batch) add(key, value model.Slice) {
-	keyValuePair := model.KeyValuePair{Key: key, Value: value}
+	batch.addWithExpiry(key, value, model.NeverExpires)
+}
+
+func (batch *Batch) addWithExpiry(key, value model.Slice, expiresAt int64) {
+	keyValuePair := model.KeyValuePair{Key: key, Value: value, ExpiresAt: expiresAt}
 	batch.keyValuePairs = append(batch.keyValuePairs, keyValuePair)
 	batch.persistentLogSlice.Add(log.NewPersistentLogSlice(keyValuePair))
 }
@@ -0,0 +1,51 @@
+package db
+
+import "sync"
+
+const liveBatchesCapacity = 64
+
+type ChangeFeed struct {
+	liveBatchesBySubscription map[*Subscription]chan CommittedBatch
+	lock                      sync.Mutex
+}
+
+func newChangeFeed() *ChangeFeed {
+	return &ChangeFeed{
+		liveBatchesBySubscription: make(map[*Subscription]chan CommittedBatch),
+	}
+}
+
+// publish never blocks the executor. A subscription whose buffer is full is dropped from the feed and its
+// channel is closed, the subscription then catches up from the WAL using its cursor.
+func (changeFeed *ChangeFeed) publish(batch CommittedBatch) {
+	changeFeed.lock.Lock()
+	defer changeFeed.lock.Unlock()
+
+	for subscription, liveBatches := range changeFeed.liveBatchesBySubscription {
+		select {
+		case liveBatches <- batch:
+		default:
+			delete(changeFeed.liveBatchesBySubscription, subscription)
+			close(liveBatches)
+		}
+	}
+}
+
+func (changeFeed *ChangeFeed) register(subscription *Subscription) <-chan CommittedBatch {
+	changeFeed.lock.Lock()
+	defer changeFeed.lock.Unlock()
+
+	if subscription.isClosed() {
+		return nil
+	}
+	liveBatches := make(chan CommittedBatch, liveBatchesCapacity)
+	changeFeed.liveBatchesBySubscription[subscription] = liveBatches
+	return liveBatches
+}
+
+func (changeFeed *ChangeFeed) unregister(subscription *Subscription) {
+	changeFeed.lock.Lock()
+	defer changeFeed.lock.Unlock()
+
+	delete(changeFeed.liveBatchesBySubscription, subscription)
+}
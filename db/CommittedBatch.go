@@ -0,0 +1,66 @@
+package db
+
+import (
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/log"
+)
+
+type OperationType int
+
+const (
+	OperationPut OperationType = iota
+	OperationPutWithTTL
+)
+
+type Change struct {
+	Operation    OperationType
+	KeyValuePair model.KeyValuePair
+}
+
+type CommittedBatch struct {
+	Sequence uint64
+	Changes  []Change
+}
+
+func newCommittedBatch(sequence uint64, keyValuePairs []model.KeyValuePair) CommittedBatch {
+	changes := make([]Change, 0, len(keyValuePairs))
+	for _, keyValuePair := range keyValuePairs {
+		changes = append(changes, Change{Operation: operationTypeOf(keyValuePair), KeyValuePair: keyValuePair})
+	}
+	return CommittedBatch{Sequence: sequence, Changes: changes}
+}
+
+func newCommittedBatchFromLog(transactionalEntry log.TransactionalEntry) CommittedBatch {
+	persistentKeyValuePairs := transactionalEntry.KeyValuePairs()
+	keyValuePairs := make([]model.KeyValuePair, 0, len(persistentKeyValuePairs))
+	for _, persistentKeyValuePair := range persistentKeyValuePairs {
+		keyValuePairs = append(keyValuePairs, model.KeyValuePair{
+			Key:       persistentKeyValuePair.Key.GetSlice(),
+			Value:     persistentKeyValuePair.Value.GetSlice(),
+			ExpiresAt: persistentKeyValuePair.ExpiresAt,
+		})
+	}
+	return newCommittedBatch(transactionalEntry.Sequence(), keyValuePairs)
+}
+
+func operationTypeOf(keyValuePair model.KeyValuePair) OperationType {
+	if keyValuePair.ExpiresAt != model.NeverExpires {
+		return OperationPutWithTTL
+	}
+	return OperationPut
+}
+
+// committedBatchesFrom reads the WAL on the goroutine of the caller.
+func committedBatchesFrom(reader *log.Reader, sequence uint64) ([]CommittedBatch, error) {
+	transactionalEntries, err := reader.ReadFrom(sequence)
+	if err != nil {
+		return nil, err
+	}
+	var batches []CommittedBatch
+	for _, transactionalEntry := range transactionalEntries {
+		if transactionalEntry.IsCommitted() {
+			batches = append(batches, newCommittedBatchFromLog(transactionalEntry))
+		}
+	}
+	return batches, nil
+}
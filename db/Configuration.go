@@ -1,12 +1,28 @@
 package db
 
-import "storage-engine-workshop/storage/comparator"
+import (
+	"storage-engine-workshop/storage/comparator"
+	"storage-engine-workshop/storage/compression"
+	"storage-engine-workshop/storage/extractor"
+	"storage-engine-workshop/storage/sst"
+)
 
 type Configuration struct {
 	directory           string
 	segmentMaxSizeBytes uint64
 	bufferSizeBytes     uint64
 	keyComparator       comparator.KeyComparator
+	prefixExtractor     extractor.PrefixExtractor
+	blockSize           int
+	restartInterval     int
+	compression         compression.Codec
+	compaction          sst.CompactionStyle
+	compactionFilter    sst.CompactionFilter
+	backgroundWorkers   int
+	rateLimit           int64
+	writeStalls         WriteStallOptions
+	indexCacheCapacity  int64
+	blockCacheCapacity  int64
 }
 
 func NewConfiguration(directory string, segmentMaxSizeBytes, bufferSizeBytes uint64, keyComparator comparator.KeyComparator) Configuration {
@@ -17,3 +33,110 @@ func NewConfiguration(directory string, segmentMaxSizeBytes, bufferSizeBytes uin
 		keyComparator:       keyComparator,
 	}
 }
+
+// WithPrefixExtractor returns a copy of the configuration which puts the extracted key prefixes in the bloom filters of SSTables,
+// letting ScanPrefix skip the SSTables that can not contain a prefix.
+func (configuration Configuration) WithPrefixExtractor(prefixExtractor extractor.PrefixExtractor) Configuration {
+	configuration.prefixExtractor = prefixExtractor
+	return configuration
+}
+
+// WithBlockSize returns a copy of the configuration which writes SSTable data blocks of roughly blockSize bytes.
+func (configuration Configuration) WithBlockSize(blockSize int) Configuration {
+	configuration.blockSize = blockSize
+	return configuration
+}
+
+// WithRestartInterval returns a copy of the configuration which stores every restartInterval-th key of an SSTable data block in full,
+// the keys in between store only the suffix they do not share with the previous key.
+func (configuration Configuration) WithRestartInterval(restartInterval int) Configuration {
+	configuration.restartInterval = restartInterval
+	return configuration
+}
+
+// WithCompression returns a copy of the configuration which compresses the SSTable data blocks written from now on with the codec,
+// a block records the codec that compressed it so SSTables written with another codec remain readable.
+func (configuration Configuration) WithCompression(codec compression.Codec) Configuration {
+	configuration.compression = codec
+	return configuration
+}
+
+// WithSizeTieredCompaction returns a copy of the configuration which merges runs of similarly sized SSTables as per the options.
+func (configuration Configuration) WithSizeTieredCompaction(options sst.SizeTieredCompactionOptions) Configuration {
+	configuration.compaction = options
+	return configuration
+}
+
+// WithLeveledCompaction returns a copy of the configuration which pushes flushed SSTables down levels of SSTables with disjoint key ranges,
+// a get reads at most one SSTable in every level below level 0.
+func (configuration Configuration) WithLeveledCompaction(options sst.LeveledCompactionOptions) Configuration {
+	configuration.compaction = options
+	return configuration
+}
+
+// WithFIFOCompaction returns a copy of the configuration which never merges SSTables, it drops the oldest SSTables
+// once they are older than the maximum age or once the SSTables exceed the maximum total size.
+func (configuration Configuration) WithFIFOCompaction(options sst.FIFOCompactionOptions) Configuration {
+	configuration.compaction = options
+	return configuration
+}
+
+// WithTimeWindowCompaction returns a copy of the configuration which merges only the SSTables created in the same time window.
+func (configuration Configuration) WithTimeWindowCompaction(options sst.TimeWindowCompactionOptions) Configuration {
+	configuration.compaction = options
+	return configuration
+}
+
+// WithCompactionFilter returns a copy of the configuration which lets the filter keep, remove or change every key/value pair
+// written to an SSTable by a memTable flush or by a compaction.
+func (configuration Configuration) WithCompactionFilter(compactionFilter sst.CompactionFilter) Configuration {
+	configuration.compactionFilter = compactionFilter
+	return configuration
+}
+
+// WithBackgroundWorkers returns a copy of the configuration which flushes memTables and compacts SSTables on backgroundWorkers goroutines,
+// a queued flush runs before a queued compaction.
+func (configuration Configuration) WithBackgroundWorkers(backgroundWorkers int) Configuration {
+	configuration.backgroundWorkers = backgroundWorkers
+	return configuration
+}
+
+// WithRateLimit returns a copy of the configuration which writes SSTables, flushed or compacted, at most at bytesPerSecond.
+func (configuration Configuration) WithRateLimit(bytesPerSecond int64) Configuration {
+	configuration.rateLimit = bytesPerSecond
+	return configuration
+}
+
+// WithWriteStalls returns a copy of the configuration which delays or blocks commits as per the options,
+// when the memTables waiting to be flushed or the SSTables waiting to be merged pile up.
+func (configuration Configuration) WithWriteStalls(options WriteStallOptions) Configuration {
+	configuration.writeStalls = options
+	return configuration
+}
+
+// WithIndexCacheCapacity returns a copy of the configuration which keeps SSTable index blocks resident within indexCacheCapacity bytes.
+func (configuration Configuration) WithIndexCacheCapacity(indexCacheCapacity int64) Configuration {
+	configuration.indexCacheCapacity = indexCacheCapacity
+	return configuration
+}
+
+// WithBlockCacheCapacity returns a copy of the configuration which caches SSTable data blocks within blockCacheCapacity bytes,
+// the block cache is shared by all the SSTables.
+func (configuration Configuration) WithBlockCacheCapacity(blockCacheCapacity int64) Configuration {
+	configuration.blockCacheCapacity = blockCacheCapacity
+	return configuration
+}
+
+func (configuration Configuration) ssTableOptions() sst.SSTableOptions {
+	return sst.SSTableOptions{
+		PrefixExtractor:         configuration.prefixExtractor,
+		BlockSize:               configuration.blockSize,
+		RestartInterval:         configuration.restartInterval,
+		Compression:             configuration.compression,
+		Compaction:              configuration.compaction,
+		CompactionFilter:        configuration.compactionFilter,
+		RateLimitBytesPerSecond: configuration.rateLimit,
+		IndexCacheCapacity:      configuration.indexCacheCapacity,
+		BlockCacheCapacity:      configuration.blockCacheCapacity,
+	}
+}
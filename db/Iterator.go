@@ -0,0 +1,118 @@
+package db
+
+import (
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"storage-engine-workshop/storage/iterator"
+	"time"
+)
+
+// Iterator walks keys in [lowerBound, upperBound) in comparator order, an empty bound leaves that side open.
+// Expired keys are skipped in both directions and do not expose older versions of the same key.
+// The iterator holds the version it was created from until it is closed.
+type Iterator struct {
+	version       *Version
+	source        *iterator.MergingIterator
+	lowerBound    model.Slice
+	upperBound    model.Slice
+	keyComparator comparator.KeyComparator
+	now           time.Time
+}
+
+func newIterator(version *Version, sources []iterator.Iterator, lowerBound, upperBound model.Slice, keyComparator comparator.KeyComparator) *Iterator {
+	iterator := &Iterator{
+		version:       version,
+		source:        iterator.NewMergingIterator(sources, keyComparator),
+		lowerBound:    lowerBound,
+		upperBound:    upperBound,
+		keyComparator: keyComparator,
+		now:           time.Now(),
+	}
+	iterator.Seek(lowerBound)
+	return iterator
+}
+
+func (iterator *Iterator) Seek(key model.Slice) {
+	if iterator.lowerBound.Size() > 0 && iterator.keyComparator.Compare(key, iterator.lowerBound) < 0 {
+		key = iterator.lowerBound
+	}
+	if key.Size() == 0 {
+		iterator.source.SeekToFirst()
+	} else {
+		iterator.source.Seek(key)
+	}
+	iterator.skipExpired()
+}
+
+func (iterator *Iterator) SeekToLast() {
+	if iterator.upperBound.Size() == 0 {
+		iterator.source.SeekToLast()
+		iterator.skipExpiredBackward()
+		return
+	}
+	iterator.source.SeekForPrev(iterator.upperBound)
+	if iterator.source.Valid() && iterator.keyComparator.Compare(iterator.source.Key(), iterator.upperBound) >= 0 {
+		iterator.source.Prev()
+	}
+	iterator.skipExpiredBackward()
+}
+
+func (iterator *Iterator) SeekForPrev(key model.Slice) {
+	if iterator.upperBound.Size() > 0 && iterator.keyComparator.Compare(key, iterator.upperBound) >= 0 {
+		iterator.SeekToLast()
+		return
+	}
+	iterator.source.SeekForPrev(key)
+	iterator.skipExpiredBackward()
+}
+
+func (iterator *Iterator) Next() {
+	iterator.source.Next()
+	iterator.skipExpired()
+}
+
+func (iterator *Iterator) Prev() {
+	iterator.source.Prev()
+	iterator.skipExpiredBackward()
+}
+
+func (iterator *Iterator) Valid() bool {
+	if !iterator.source.Valid() {
+		return false
+	}
+	if iterator.upperBound.Size() > 0 && iterator.keyComparator.Compare(iterator.source.Key(), iterator.upperBound) >= 0 {
+		return false
+	}
+	if iterator.lowerBound.Size() > 0 && iterator.keyComparator.Compare(iterator.source.Key(), iterator.lowerBound) < 0 {
+		return false
+	}
+	return true
+}
+
+func (iterator *Iterator) Key() model.Slice {
+	return iterator.source.Key()
+}
+
+func (iterator *Iterator) Value() model.Slice {
+	return iterator.source.Value()
+}
+
+func (iterator *Iterator) Close() {
+	iterator.source.Close()
+	if iterator.version != nil {
+		iterator.version.release()
+		iterator.version = nil
+	}
+}
+
+func (iterator *Iterator) skipExpired() {
+	for iterator.Valid() && model.IsExpired(iterator.source.ExpiresAt(), iterator.now) {
+		iterator.source.Next()
+	}
+}
+
+func (iterator *Iterator) skipExpiredBackward() {
+	for iterator.Valid() && model.IsExpired(iterator.source.ExpiresAt(), iterator.now) {
+		iterator.source.Prev()
+	}
+}
@@ -0,0 +1,229 @@
+package db
+
+import (
+	"os"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"storage-engine-workshop/storage/extractor"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestIteratesOverKeysAcrossMemTablesAndSSTablesInOrder(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 256
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{})
+	db, _ := NewKeyValueDb(configuration)
+
+	keyUsing := func(count int) model.Slice {
+		return model.NewSlice([]byte("Key-" + strconv.Itoa(1000+count)))
+	}
+	for count := 1; count <= 100; count++ {
+		commitKeyValue(db, keyUsing(count).AsString(), "Value-"+strconv.Itoa(count))
+	}
+	allowFlushingSSTable()
+
+	iterator := db.newReadonlyTransaction().NewIterator(model.NilSlice(), model.NilSlice())
+	defer iterator.Close()
+
+	count := 1
+	for ; iterator.Valid(); iterator.Next() {
+		if iterator.Key().AsString() != keyUsing(count).AsString() {
+			t.Fatalf("Expected key to be %v, received %v", keyUsing(count).AsString(), iterator.Key().AsString())
+		}
+		count = count + 1
+	}
+	if count != 101 {
+		t.Fatalf("Expected to iterate over %v keys, iterated over %v", 100, count-1)
+	}
+}
+
+func TestIteratesOverKeysWithinBounds(t *testing.T) {
+	db, directory := initKeyValueDb()
+	defer os.RemoveAll(directory)
+
+	for _, key := range []string{"A", "B", "C", "D", "E"} {
+		commitKeyValue(db, key, "Value-"+key)
+	}
+
+	iterator := db.newReadonlyTransaction().NewIterator(model.NewSlice([]byte("B")), model.NewSlice([]byte("D")))
+	defer iterator.Close()
+
+	var keys string
+	for ; iterator.Valid(); iterator.Next() {
+		keys = keys + iterator.Key().AsString()
+	}
+	if keys != "BC" {
+		t.Fatalf("Expected keys to be %v, received %v", "BC", keys)
+	}
+}
+
+func TestIteratesWithNewerValuesShadowingOlderOnes(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 16
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{})
+	db, _ := NewKeyValueDb(configuration)
+
+	commitKeyValue(db, "Company", "Old")
+	commitKeyValue(db, "Field", "Storage")
+	allowFlushingSSTable()
+	commitKeyValue(db, "Company", "New")
+
+	iterator := db.newReadonlyTransaction().NewIterator(model.NilSlice(), model.NilSlice())
+	defer iterator.Close()
+
+	if !iterator.Valid() || iterator.Key().AsString() != "Company" || iterator.Value().AsString() != "New" {
+		t.Fatalf("Expected first key/value to be %v/%v", "Company", "New")
+	}
+	iterator.Next()
+	if !iterator.Valid() || iterator.Key().AsString() != "Field" {
+		t.Fatalf("Expected second key to be %v", "Field")
+	}
+}
+
+func TestIteratorSkipsExpiredKeys(t *testing.T) {
+	db, directory := initKeyValueDb()
+	defer os.RemoveAll(directory)
+
+	txn := db.newTransaction()
+	_ = txn.Put(model.NewSlice([]byte("A")), model.NewSlice([]byte("a")))
+	_ = txn.PutWithTTL(model.NewSlice([]byte("B")), model.NewSlice([]byte("b")), time.Millisecond)
+	_ = txn.Put(model.NewSlice([]byte("C")), model.NewSlice([]byte("c")))
+	_ = txn.Commit()
+
+	time.Sleep(5 * time.Millisecond)
+
+	iterator := db.newReadonlyTransaction().NewIterator(model.NilSlice(), model.NilSlice())
+	defer iterator.Close()
+
+	var keys string
+	for ; iterator.Valid(); iterator.Next() {
+		keys = keys + iterator.Key().AsString()
+	}
+	if keys != "AC" {
+		t.Fatalf("Expected keys to be %v, received %v", "AC", keys)
+	}
+}
+
+func TestIteratesInReverseAcrossMemTablesAndSSTables(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 256
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{})
+	db, _ := NewKeyValueDb(configuration)
+
+	keyUsing := func(count int) model.Slice {
+		return model.NewSlice([]byte("Key-" + strconv.Itoa(1000+count)))
+	}
+	for count := 1; count <= 100; count++ {
+		commitKeyValue(db, keyUsing(count).AsString(), "Value-"+strconv.Itoa(count))
+	}
+	allowFlushingSSTable()
+
+	iterator := db.newReadonlyTransaction().NewIterator(model.NilSlice(), model.NilSlice())
+	defer iterator.Close()
+
+	count := 100
+	for iterator.SeekToLast(); iterator.Valid(); iterator.Prev() {
+		if iterator.Key().AsString() != keyUsing(count).AsString() {
+			t.Fatalf("Expected key to be %v, received %v", keyUsing(count).AsString(), iterator.Key().AsString())
+		}
+		count = count - 1
+	}
+	if count != 0 {
+		t.Fatalf("Expected to iterate over %v keys, iterated over %v", 100, 100-count)
+	}
+}
+
+func TestIteratesInReverseWithinBounds(t *testing.T) {
+	db, directory := initKeyValueDb()
+	defer os.RemoveAll(directory)
+
+	for _, key := range []string{"A", "B", "C", "D", "E"} {
+		commitKeyValue(db, key, "Value-"+key)
+	}
+
+	iterator := db.newReadonlyTransaction().NewIterator(model.NewSlice([]byte("B")), model.NewSlice([]byte("D")))
+	defer iterator.Close()
+
+	var keys string
+	for iterator.SeekToLast(); iterator.Valid(); iterator.Prev() {
+		keys = keys + iterator.Key().AsString()
+	}
+	if keys != "CB" {
+		t.Fatalf("Expected keys to be %v, received %v", "CB", keys)
+	}
+}
+
+func TestSeeksForPrevSkippingExpiredKeys(t *testing.T) {
+	db, directory := initKeyValueDb()
+	defer os.RemoveAll(directory)
+
+	txn := db.newTransaction()
+	_ = txn.Put(model.NewSlice([]byte("A")), model.NewSlice([]byte("a")))
+	_ = txn.PutWithTTL(model.NewSlice([]byte("B")), model.NewSlice([]byte("b")), time.Millisecond)
+	_ = txn.Put(model.NewSlice([]byte("C")), model.NewSlice([]byte("c")))
+	_ = txn.Commit()
+
+	time.Sleep(5 * time.Millisecond)
+
+	iterator := db.newReadonlyTransaction().NewIterator(model.NilSlice(), model.NilSlice())
+	defer iterator.Close()
+
+	iterator.SeekForPrev(model.NewSlice([]byte("BB")))
+	if !iterator.Valid() || iterator.Key().AsString() != "A" {
+		t.Fatalf("Expected seek for prev to skip the expired key and position at %v", "A")
+	}
+}
+
+func TestScansKeysWithAPrefixAcrossMemTablesAndSSTables(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 64
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{}).
+		WithPrefixExtractor(extractor.NewDelimiterPrefixExtractor(':'))
+	db, _ := NewKeyValueDb(configuration)
+
+	commitKeyValue(db, "tenant-a:disk:1", "HDD")
+	commitKeyValue(db, "tenant-b:disk:1", "SSD")
+	commitKeyValue(db, "tenant-a:disk:2", "NVMe")
+	allowFlushingSSTable()
+	commitKeyValue(db, "tenant-c:disk:1", "Tape")
+	commitKeyValue(db, "tenant-a:disk:3", "Flash")
+
+	iterator := db.newReadonlyTransaction().ScanPrefix(model.NewSlice([]byte("tenant-a:")))
+	defer iterator.Close()
+
+	var values []string
+	for ; iterator.Valid(); iterator.Next() {
+		values = append(values, iterator.Value().AsString())
+	}
+	if len(values) != 3 || values[0] != "HDD" || values[1] != "NVMe" || values[2] != "Flash" {
+		t.Fatalf("Expected values to be %v, received %v", []string{"HDD", "NVMe", "Flash"}, values)
+	}
+}
+
+func TestComputesTheSuccessorOfAPrefix(t *testing.T) {
+	successor := prefixSuccessor(model.NewSlice([]byte{'a', 0xff}))
+	if string(successor.GetRawContent()) != "b" {
+		t.Fatalf("Expected successor to be %v, received %v", "b", successor.AsString())
+	}
+	if prefixSuccessor(model.NewSlice([]byte{0xff, 0xff})).Size() != 0 {
+		t.Fatalf("Expected a prefix of 0xff bytes to have no successor")
+	}
+}
@@ -1,5 +1,10 @@
 package db
 
+import (
+	"context"
+	"storage-engine-workshop/db/model"
+)
+
 type KeyValueDb struct {
 	executor *RequestExecutor
 }
@@ -21,3 +26,40 @@ func (db *KeyValueDb) newTransaction() *Transaction {
 func (db *KeyValueDb) newReadonlyTransaction() ReadonlyTransaction {
 	return newReadonlyTransaction(db.executor)
 }
+
+func (db *KeyValueDb) Statistics() Statistics {
+	return db.executor.workSpace.statistics()
+}
+
+// CompactRange rewrites every SSTable which overlaps [start, end), an empty bound leaves that side open.
+// It drops the shadowed versions and the expired key/value pairs, the key/value pairs still in memTables are not compacted.
+func (db *KeyValueDb) CompactRange(start, end model.Slice) (CompactRangeStatistics, error) {
+	return db.CompactRangeContext(context.Background(), start, end)
+}
+
+// CompactRangeContext blocks till the range is compacted or ctx is done. Once ctx is done it stops waiting for a running background compaction
+// and stops the compaction of the range it is running, deleting the SSTables that compaction has written, before it returns ctx.Err().
+// The compactions of the range finished till then stay in place.
+func (db *KeyValueDb) CompactRangeContext(ctx context.Context, start, end model.Slice) (CompactRangeStatistics, error) {
+	return db.executor.workSpace.compactRange(ctx, start, end)
+}
+
+// PauseCompactions keeps the background compactions from starting till they are resumed, a running compaction finishes.
+// Flushes and CompactRange are not paused.
+func (db *KeyValueDb) PauseCompactions() {
+	db.executor.workSpace.pauseCompactions()
+}
+
+func (db *KeyValueDb) ResumeCompactions() {
+	db.executor.workSpace.resumeCompactions()
+}
+
+// Close waits for the queued flushes and the running compaction to finish, stops the background workers and closes the write-ahead log.
+// The db must not be used after it is closed.
+func (db *KeyValueDb) Close() {
+	db.executor.workSpace.close()
+}
+
+func (db *KeyValueDb) Subscribe(fromSequence uint64) *Subscription {
+	return newSubscription(fromSequence, db.executor)
+}
@@ -117,3 +117,15 @@ func allowFlushingSSTable() {
 func allowFlushingSSTableFor(duration time.Duration) {
 	time.Sleep(duration)
 }
+
+// eventually polls the condition till it holds or 5 seconds pass, it answers whether the condition held.
+func eventually(condition func() bool) bool {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return condition()
+}
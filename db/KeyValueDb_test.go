@@ -1,11 +1,15 @@
 package db
 
 import (
+	"io/ioutil"
 	"log"
 	"os"
+	"path"
 	"storage-engine-workshop/db/model"
 	"storage-engine-workshop/storage/comparator"
+	"storage-engine-workshop/storage/sst"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -73,3 +77,175 @@ func TestPuts20KeysValuesAndGetByKeys(t *testing.T) {
 		}
 	}
 }
+
+func TestCompactsARangeOfSSTablesKeepingTheNewestValues(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 32
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{})
+	db, _ := NewKeyValueDb(configuration)
+
+	for count := 1; count <= 12; count++ {
+		txn := db.newTransaction()
+		_ = txn.Put(model.NewSlice([]byte("Key-"+strconv.Itoa(count%3))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+		if err := txn.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	allowFlushingSSTable()
+
+	statistics, err := db.CompactRange(model.NewSlice([]byte("Key-0")), model.NewSlice([]byte("Key-3")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statistics.BytesRead == 0 || statistics.BytesWritten == 0 || statistics.BytesWritten >= statistics.BytesRead {
+		t.Fatalf("Expected compaction to read more bytes than it writes, received %v bytes read and %v bytes written", statistics.BytesRead, statistics.BytesWritten)
+	}
+	readonlyTxn := db.newReadonlyTransaction()
+	for count := 10; count <= 12; count++ {
+		if getResult := readonlyTxn.Get(model.NewSlice([]byte("Key-" + strconv.Itoa(count%3)))); getResult.Value.AsString() != "Value-"+strconv.Itoa(count) {
+			t.Fatalf("Expected value to be %v, received %v", "Value-"+strconv.Itoa(count), getResult.Value.AsString())
+		}
+	}
+}
+
+func TestRemovesKeysRejectedByTheCompactionFilterOnceTheyAreFlushed(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 32
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	removingDeletedTenants := sst.CompactionFilterFunc(func(key, value model.Slice) (sst.CompactionDecision, model.Slice) {
+		if strings.HasPrefix(key.AsString(), "deleted-tenant:") {
+			return sst.RemoveKey, model.NilSlice()
+		}
+		return sst.KeepValue, value
+	})
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{}).WithCompactionFilter(removingDeletedTenants)
+	db, _ := NewKeyValueDb(configuration)
+
+	for _, key := range []string{"deleted-tenant:1", "tenant:1", "deleted-tenant:2", "tenant:2"} {
+		txn := db.newTransaction()
+		_ = txn.Put(model.NewSlice([]byte(key)), model.NewSlice([]byte("Value")))
+		if err := txn.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	allowFlushingSSTable()
+
+	readonlyTxn := db.newReadonlyTransaction()
+	if getResult := readonlyTxn.Get(model.NewSlice([]byte("deleted-tenant:1"))); getResult.Exists {
+		t.Fatalf("Expected key %v removed by the compaction filter to be missing", "deleted-tenant:1")
+	}
+	if getResult := readonlyTxn.Get(model.NewSlice([]byte("tenant:1"))); getResult.Value.AsString() != "Value" {
+		t.Fatalf("Expected value to be %v, received %v", "Value", getResult.Value.AsString())
+	}
+}
+
+func TestDoesNotResurrectAnOlderVersionOfAKeyRemovedByTheCompactionFilter(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 32
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	removingDeletedTenants := sst.CompactionFilterFunc(func(key, value model.Slice) (sst.CompactionDecision, model.Slice) {
+		if value.AsString() == "Deleted" {
+			return sst.RemoveKey, model.NilSlice()
+		}
+		return sst.KeepValue, value
+	})
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{}).WithCompactionFilter(removingDeletedTenants)
+	db, _ := NewKeyValueDb(configuration)
+
+	for count, value := range []string{"Active", "Deleted"} {
+		txn := db.newTransaction()
+		_ = txn.Put(model.NewSlice([]byte("tenant:1")), model.NewSlice([]byte(value)))
+		_ = txn.Put(model.NewSlice([]byte("tenant:"+strconv.Itoa(count+2))), model.NewSlice([]byte("Active, filling the memTable up")))
+		if err := txn.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	txn := db.newTransaction()
+	_ = txn.Put(model.NewSlice([]byte("tenant:4")), model.NewSlice([]byte("Active")))
+	if err := txn.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	allowFlushingSSTable()
+
+	if getResult := db.newReadonlyTransaction().Get(model.NewSlice([]byte("tenant:1"))); getResult.Exists {
+		t.Fatalf("Expected the older version %v of the removed key to stay shadowed, received %v", "Active", getResult.Value.AsString())
+	}
+
+	if _, err := db.CompactRange(model.NilSlice(), model.NilSlice()); err != nil {
+		t.Fatal(err)
+	}
+	if getResult := db.newReadonlyTransaction().Get(model.NewSlice([]byte("tenant:1"))); getResult.Exists {
+		t.Fatalf("Expected the removed key to stay missing once compacted, received %v", getResult.Value.AsString())
+	}
+	if getResult := db.newReadonlyTransaction().Get(model.NewSlice([]byte("tenant:4"))); getResult.Value.AsString() != "Active" {
+		t.Fatalf("Expected value to be %v, received %v", "Active", getResult.Value.AsString())
+	}
+}
+
+func TestCompactsSSTablesOnlyOnceCompactionsAreResumed(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 32
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{}).WithBackgroundWorkers(1)
+	db, _ := NewKeyValueDb(configuration)
+	db.PauseCompactions()
+
+	for count := 1; count <= 20; count++ {
+		txn := db.newTransaction()
+		_ = txn.Put(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+		if err := txn.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	workspace := db.executor.workSpace
+	allFlushed := eventually(func() bool {
+		immutableMemTables, _ := workspace.pendingWork()
+		return immutableMemTables == 0
+	})
+	pausedLevel0SSTables := workspace.ssTables.TotalLevel0SSTables()
+	if !allFlushed || pausedLevel0SSTables < 4 {
+		t.Fatalf("Expected at least %v SSTables to be flushed and none compacted while paused, found %v SSTables", 4, pausedLevel0SSTables)
+	}
+	db.ResumeCompactions()
+
+	if !eventually(func() bool { return workspace.ssTables.TotalLevel0SSTables() < pausedLevel0SSTables }) {
+		t.Fatalf("Expected SSTables to be compacted once resumed, found %v SSTables while paused and %v once resumed", pausedLevel0SSTables, workspace.ssTables.TotalLevel0SSTables())
+	}
+}
+
+func TestClosesTheDbOnceTheQueuedFlushesFinish(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 32
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{}).WithBackgroundWorkers(1)
+	db, _ := NewKeyValueDb(configuration)
+	for count := 1; count <= 4; count++ {
+		txn := db.newTransaction()
+		_ = txn.Put(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+		if err := txn.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	ssTableFiles, _ := ioutil.ReadDir(path.Join(directory, "sst"))
+	if len(ssTableFiles) == 0 {
+		t.Fatalf("Expected the queued flushes to finish before the db is closed, found no SSTable files")
+	}
+}
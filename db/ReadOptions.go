@@ -0,0 +1,19 @@
+package db
+
+import (
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/sst"
+)
+
+// ReadOptions tunes a single read, the zero value fills the block cache with the SSTable blocks that the read loads.
+type ReadOptions struct {
+	SkipFillingBlockCache bool
+}
+
+func (readOptions ReadOptions) ssTableReadOptions(lowerBound, upperBound model.Slice) sst.ReadOptions {
+	return sst.ReadOptions{
+		SkipFillingCache: readOptions.SkipFillingBlockCache,
+		LowerBound:       lowerBound,
+		UpperBound:       upperBound,
+	}
+}
@@ -1,18 +1,21 @@
 package db
 
 import (
+	"context"
 	"storage-engine-workshop/db/model"
 )
 
 type RequestExecutor struct {
 	requestChannel chan interface{}
 	workSpace      *Workspace
+	changeFeed     *ChangeFeed
 }
 
 func newRequestExecutor(workSpace *Workspace) *RequestExecutor {
 	executor := &RequestExecutor{
 		requestChannel: make(chan interface{}),
 		workSpace:      workSpace,
+		changeFeed:     newChangeFeed(),
 	}
 	executor.init()
 	return executor
@@ -20,16 +23,23 @@ func newRequestExecutor(workSpace *Workspace) *RequestExecutor {
 
 func (executor *RequestExecutor) init() {
 	put := func(putRequest PutRequest) {
-		putRequest.ResponseChannel <- executor.workSpace.put(putRequest.Batch)
+		if err := putRequest.Context.Err(); err != nil {
+			putRequest.ResponseChannel <- err
+			close(putRequest.ResponseChannel)
+			return
+		}
+		err := executor.workSpace.put(putRequest.Batch)
+		if err == nil {
+			executor.changeFeed.publish(newCommittedBatch(executor.workSpace.lastSequence(), putRequest.Batch.keyValuePairs))
+		}
+		putRequest.ResponseChannel <- err
 		close(putRequest.ResponseChannel)
 	}
-	get := func(getRequest GetRequest) {
-		getRequest.ResponseChannel <- executor.workSpace.get(getRequest.Key)
-		close(getRequest.ResponseChannel)
-	}
-	multiGet := func(multiGetRequest MultiGetRequest) {
-		multiGetRequest.ResponseChannel <- executor.workSpace.multiGet(multiGetRequest.Keys)
-		close(multiGetRequest.ResponseChannel)
+	subscribe := func(subscribeRequest SubscribeRequest) {
+		reader := executor.workSpace.walReader()
+		liveBatches := executor.changeFeed.register(subscribeRequest.Subscription)
+		subscribeRequest.ResponseChannel <- SubscribeResponse{Reader: reader, LiveBatches: liveBatches}
+		close(subscribeRequest.ResponseChannel)
 	}
 
 	go func() {
@@ -37,29 +47,48 @@ func (executor *RequestExecutor) init() {
 			request := <-executor.requestChannel
 			if putRequest, ok := request.(PutRequest); ok {
 				put(putRequest)
-			} else if getRequest, ok := request.(GetRequest); ok {
-				get(getRequest)
-			} else if multiGetRequest, ok := request.(MultiGetRequest); ok {
-				multiGet(multiGetRequest)
+			} else if subscribeRequest, ok := request.(SubscribeRequest); ok {
+				subscribe(subscribeRequest)
 			}
 		}
 	}()
 }
 
 func (executor *RequestExecutor) put(batch *Batch) chan error {
-	responseChannel := make(chan error)
-	executor.requestChannel <- PutRequest{Batch: batch, ResponseChannel: responseChannel}
-	return responseChannel
+	return executor.putContext(context.Background(), batch)
 }
 
-func (executor *RequestExecutor) get(key model.Slice) chan model.GetResult {
-	responseChannel := make(chan model.GetResult)
-	executor.requestChannel <- GetRequest{Key: key, ResponseChannel: responseChannel}
+// putContext stops waiting to enqueue the request once ctx is done. The response channel is buffered
+// so that the executor never blocks on a caller which has stopped waiting for the response.
+func (executor *RequestExecutor) putContext(ctx context.Context, batch *Batch) chan error {
+	responseChannel := make(chan error, 1)
+	select {
+	case executor.requestChannel <- PutRequest{Context: ctx, Batch: batch, ResponseChannel: responseChannel}:
+	case <-ctx.Done():
+		responseChannel <- ctx.Err()
+		close(responseChannel)
+	}
 	return responseChannel
 }
 
-func (executor *RequestExecutor) multiGet(keys []model.Slice) chan []model.GetResult {
-	responseChannel := make(chan []model.GetResult)
-	executor.requestChannel <- MultiGetRequest{Keys: keys, ResponseChannel: responseChannel}
+// get, multiGet and newIterator do not go through the request goroutine, they run on the goroutine of the caller
+// against the current version of the workspace.
+func (executor *RequestExecutor) get(key model.Slice) model.GetResult {
+	return executor.workSpace.get(key)
+}
+
+func (executor *RequestExecutor) multiGet(keys []model.Slice) []model.GetResult {
+	return executor.workSpace.multiGet(keys)
+}
+
+func (executor *RequestExecutor) newIterator(lowerBound, upperBound, prefix model.Slice, readOptions ReadOptions) *Iterator {
+	return executor.workSpace.newIterator(lowerBound, upperBound, prefix, readOptions)
+}
+
+// subscribe registers the subscription for live batches and returns a reader of the batches committed before it was registered,
+// no batch is missed or repeated between the two.
+func (executor *RequestExecutor) subscribe(subscription *Subscription) chan SubscribeResponse {
+	responseChannel := make(chan SubscribeResponse, 1)
+	executor.requestChannel <- SubscribeRequest{Subscription: subscription, ResponseChannel: responseChannel}
 	return responseChannel
 }
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"storage-engine-workshop/db/model"
@@ -40,7 +41,7 @@ func TestPutFollowedByGet(t *testing.T) {
 
 	go func() {
 		defer wg.Done()
-		getResult := <-executor.get(model.NewSlice([]byte("Company")))
+		getResult := executor.get(model.NewSlice([]byte("Company")))
 		if getResult.Value.AsString() != "TW" {
 			t.Errorf(fmt.Sprintf("Expected value to be %v, received %v", "TW", getResult.Value.AsString()))
 		}
@@ -64,7 +65,7 @@ func TestPutAndGetConcurrently(t *testing.T) {
 
 	go func() {
 		defer wg.Done()
-		getResult := <-executor.get(model.NewSlice([]byte("Company")))
+		getResult := executor.get(model.NewSlice([]byte("Company")))
 		if getResult.Exists && getResult.Value.AsString() != "TW" {
 			t.Errorf(fmt.Sprintf("Expected value to be %v, received %v", "TW", getResult.Value.AsString()))
 		}
@@ -95,7 +96,7 @@ func TestMultiGetFollowedByPut(t *testing.T) {
 			"Company": "TW",
 			"Field":   "Storage engine",
 		}
-		multiGetResult := <-executor.multiGet([]model.Slice{model.NewSlice([]byte("Company")), model.NewSlice([]byte("Field"))})
+		multiGetResult := executor.multiGet([]model.Slice{model.NewSlice([]byte("Company")), model.NewSlice([]byte("Field"))})
 		for _, result := range multiGetResult {
 			if result.Value.AsString() != expectedValueByKey[result.Key.AsString()] {
 				t.Errorf(fmt.Sprintf("Expected value to be %v, received %v", expectedValueByKey[result.Key.AsString()], result.Value.AsString()))
@@ -134,7 +135,7 @@ func TestPutMultipleConcurrentBatches(t *testing.T) {
 
 	for goroutineId := 1; goroutineId <= 10; goroutineId++ {
 		for index := 1; index <= 200; index++ {
-			getResult := <-executor.get(keyUsing(goroutineId, index))
+			getResult := executor.get(keyUsing(goroutineId, index))
 			expectedValue := valueUsing(goroutineId, index)
 			if getResult.Value.AsString() != expectedValue.AsString() {
 				t.Fatalf("Expected value to be %v, received %v", expectedValue.AsString(), getResult.Value.AsString())
@@ -165,7 +166,7 @@ func TestPutAndMultiGetConcurrently(t *testing.T) {
 			"Company": "TW",
 			"Field":   "Storage engine",
 		}
-		multiGetResult := <-executor.multiGet([]model.Slice{model.NewSlice([]byte("Company")), model.NewSlice([]byte("Field"))})
+		multiGetResult := executor.multiGet([]model.Slice{model.NewSlice([]byte("Company")), model.NewSlice([]byte("Field"))})
 		for _, result := range multiGetResult {
 			if result.Exists && result.Value.AsString() != expectedValueByKey[result.Key.AsString()] {
 				t.Errorf(fmt.Sprintf("Expected value to be %v, received %v", expectedValueByKey[result.Key.AsString()], result.Value.AsString()))
@@ -175,3 +176,126 @@ func TestPutAndMultiGetConcurrently(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestGetsConcurrentlyWhileMemTablesAreSwappedAndFlushed(t *testing.T) {
+	var wg sync.WaitGroup
+	executor, directory := initRequestExecutor()
+	defer os.RemoveAll(directory)
+
+	keyUsing := func(index int) model.Slice {
+		return model.NewSlice([]byte("Key-" + strconv.Itoa(index)))
+	}
+	valueUsing := func(index int) model.Slice {
+		return model.NewSlice([]byte("Value-" + strconv.Itoa(index)))
+	}
+	for index := 1; index <= 100; index++ {
+		batch := NewBatch()
+		batch.add(keyUsing(index), valueUsing(index))
+		<-executor.put(batch)
+	}
+
+	wg.Add(9)
+	go func() {
+		defer wg.Done()
+		for index := 101; index <= 500; index++ {
+			batch := NewBatch()
+			batch.add(keyUsing(index), valueUsing(index))
+			<-executor.put(batch)
+		}
+	}()
+	for readerId := 1; readerId <= 8; readerId++ {
+		go func() {
+			defer wg.Done()
+			for index := 1; index <= 100; index++ {
+				getResult := executor.get(keyUsing(index))
+				if getResult.Value.AsString() != valueUsing(index).AsString() {
+					t.Errorf(fmt.Sprintf("Expected value to be %v, received %v", valueUsing(index).AsString(), getResult.Value.AsString()))
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestGetsProceedWhileAWriteHoldsTheRequestGoroutine(t *testing.T) {
+	executor, directory := initRequestExecutor()
+	defer os.RemoveAll(directory)
+
+	batch := NewBatch()
+	batch.add(model.NewSlice([]byte("Company")), model.NewSlice([]byte("TW")))
+	<-executor.put(batch)
+
+	heldResponse := make(chan error)
+	heldBatch := NewBatch()
+	heldBatch.add(model.NewSlice([]byte("Field")), model.NewSlice([]byte("Storage engine")))
+	executor.requestChannel <- PutRequest{Context: context.Background(), Batch: heldBatch, ResponseChannel: heldResponse}
+
+	blockedBatch := NewBatch()
+	blockedBatch.add(model.NewSlice([]byte("Language")), model.NewSlice([]byte("Go")))
+	blockedResponse := make(chan error, 1)
+	go func() {
+		blockedResponse <- <-executor.put(blockedBatch)
+	}()
+
+	var wg sync.WaitGroup
+	readsDone := make(chan bool)
+	wg.Add(8)
+	for readerId := 1; readerId <= 8; readerId++ {
+		go func() {
+			defer wg.Done()
+			if getResult := executor.get(model.NewSlice([]byte("Company"))); getResult.Value.AsString() != "TW" {
+				t.Errorf(fmt.Sprintf("Expected value to be %v, received %v", "TW", getResult.Value.AsString()))
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(readsDone)
+	}()
+	select {
+	case <-readsDone:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected gets to proceed while a write holds the request goroutine")
+	}
+	select {
+	case <-blockedResponse:
+		t.Fatalf("Expected the write queued behind the held write to be blocked")
+	default:
+	}
+
+	for _, response := range []chan error{heldResponse, blockedResponse} {
+		select {
+		case err := <-response:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected the writes to finish once the held write is released")
+		}
+	}
+}
+
+func BenchmarkGetsInParallel(b *testing.B) {
+	executor, directory := initRequestExecutor()
+	defer os.RemoveAll(directory)
+
+	keyUsing := func(index int) model.Slice {
+		return model.NewSlice([]byte("Key-" + strconv.Itoa(index)))
+	}
+	for index := 0; index < 1000; index++ {
+		batch := NewBatch()
+		batch.add(keyUsing(index), model.NewSlice([]byte("Value-"+strconv.Itoa(index))))
+		<-executor.put(batch)
+	}
+	allowFlushingSSTable()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		index := 0
+		for pb.Next() {
+			executor.get(keyUsing(index % 1000))
+			index = index + 1
+		}
+	})
+}
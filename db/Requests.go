@@ -1,20 +1,25 @@
 package db
 
 import (
-	"storage-engine-workshop/db/model"
+	"context"
+	"storage-engine-workshop/log"
 )
 
+// PutRequest is skipped by the executor if its Context is done before the request is dequeued.
 type PutRequest struct {
+	Context         context.Context
 	Batch           *Batch
 	ResponseChannel chan error
 }
 
-type GetRequest struct {
-	Key             model.Slice
-	ResponseChannel chan model.GetResult
+type SubscribeRequest struct {
+	Subscription    *Subscription
+	ResponseChannel chan SubscribeResponse
 }
 
-type MultiGetRequest struct {
-	Keys            []model.Slice
-	ResponseChannel chan []model.GetResult
+// SubscribeResponse hands over a reader of the WAL up to the last committed batch and the batches committed after it,
+// the WAL is read on the goroutine of the subscription so that the executor is not held up.
+type SubscribeResponse struct {
+	Reader      *log.Reader
+	LiveBatches <-chan CommittedBatch
 }
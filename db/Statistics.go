@@ -0,0 +1,16 @@
+package db
+
+// Statistics reports the hits and the misses of the block cache shared by all the SSTables,
+// the write stall commits are in at this point and the commits delayed or blocked so far by every write stall reason.
+type Statistics struct {
+	BlockCacheHits    uint64
+	BlockCacheMisses  uint64
+	CurrentWriteStall WriteStallReason
+	WriteStalls       map[WriteStallReason]WriteStallStatistics
+}
+
+// CompactRangeStatistics reports the bytes of the SSTables read and written by a manual compaction of a key range.
+type CompactRangeStatistics struct {
+	BytesRead    int64
+	BytesWritten int64
+}
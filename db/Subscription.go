@@ -0,0 +1,115 @@
+package db
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type Subscription struct {
+	nextSequence uint64
+	executor     *RequestExecutor
+	batches      chan CommittedBatch
+	done         chan struct{}
+	closeOnce    sync.Once
+	err          error
+	errLock      sync.Mutex
+}
+
+func newSubscription(fromSequence uint64, executor *RequestExecutor) *Subscription {
+	if fromSequence == 0 {
+		fromSequence = 1
+	}
+	subscription := &Subscription{
+		nextSequence: fromSequence,
+		executor:     executor,
+		batches:      make(chan CommittedBatch),
+		done:         make(chan struct{}),
+	}
+	go subscription.run()
+	return subscription
+}
+
+func (subscription *Subscription) Batches() <-chan CommittedBatch {
+	return subscription.batches
+}
+
+// Cursor returns the sequence to resume from, it is one past the sequence of the last delivered batch.
+func (subscription *Subscription) Cursor() uint64 {
+	return atomic.LoadUint64(&subscription.nextSequence)
+}
+
+func (subscription *Subscription) Err() error {
+	subscription.errLock.Lock()
+	defer subscription.errLock.Unlock()
+	return subscription.err
+}
+
+func (subscription *Subscription) Close() {
+	subscription.closeOnce.Do(func() {
+		close(subscription.done)
+		subscription.executor.changeFeed.unregister(subscription)
+	})
+}
+
+func (subscription *Subscription) run() {
+	defer close(subscription.batches)
+
+	deliver := func(batch CommittedBatch) bool {
+		if batch.Sequence < subscription.Cursor() {
+			return true
+		}
+		select {
+		case subscription.batches <- batch:
+			atomic.StoreUint64(&subscription.nextSequence, batch.Sequence+1)
+			return true
+		case <-subscription.done:
+			return false
+		}
+	}
+	tailLive := func(liveBatches <-chan CommittedBatch) bool {
+		for {
+			select {
+			case batch, ok := <-liveBatches:
+				if !ok {
+					return true
+				}
+				if !deliver(batch) {
+					return false
+				}
+			case <-subscription.done:
+				return false
+			}
+		}
+	}
+	for {
+		response := <-subscription.executor.subscribe(subscription)
+		batches, err := committedBatchesFrom(response.Reader, subscription.Cursor())
+		if err != nil {
+			subscription.setErr(err)
+			return
+		}
+		for _, batch := range batches {
+			if !deliver(batch) {
+				return
+			}
+		}
+		if !tailLive(response.LiveBatches) {
+			return
+		}
+	}
+}
+
+func (subscription *Subscription) isClosed() bool {
+	select {
+	case <-subscription.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (subscription *Subscription) setErr(err error) {
+	subscription.errLock.Lock()
+	defer subscription.errLock.Unlock()
+	subscription.err = err
+}
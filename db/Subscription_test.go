@@ -0,0 +1,144 @@
+package db
+
+import (
+	"os"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func initKeyValueDb() (*KeyValueDb, string) {
+	const segmentMaxSizeBytes uint64 = 1024
+	const bufferMaxSizeBytes uint64 = 1024
+
+	directory := tempDirectory()
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{})
+	db, _ := NewKeyValueDb(configuration)
+
+	return db, directory
+}
+
+func commitKeyValue(db *KeyValueDb, key, value string) {
+	txn := db.newTransaction()
+	_ = txn.Put(model.NewSlice([]byte(key)), model.NewSlice([]byte(value)))
+	_ = txn.Commit()
+}
+
+func receiveBatch(t *testing.T, subscription *Subscription) CommittedBatch {
+	select {
+	case batch := <-subscription.Batches():
+		return batch
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected a committed batch but received none")
+	}
+	return CommittedBatch{}
+}
+
+func TestSubscribesAndReceivesHistoricalCommittedBatches(t *testing.T) {
+	db, directory := initKeyValueDb()
+	defer os.RemoveAll(directory)
+
+	commitKeyValue(db, "Company", "TW")
+	commitKeyValue(db, "Field", "Storage engine")
+
+	subscription := db.Subscribe(1)
+	defer subscription.Close()
+
+	first, second := receiveBatch(t, subscription), receiveBatch(t, subscription)
+	if first.Sequence != 1 || first.Changes[0].KeyValuePair.Key.AsString() != "Company" {
+		t.Fatalf("Expected first batch with sequence %v and key %v, received %v and %v", 1, "Company", first.Sequence, first.Changes[0].KeyValuePair.Key.AsString())
+	}
+	if second.Sequence != 2 || second.Changes[0].KeyValuePair.Value.AsString() != "Storage engine" {
+		t.Fatalf("Expected second batch with sequence %v and value %v, received %v and %v", 2, "Storage engine", second.Sequence, second.Changes[0].KeyValuePair.Value.AsString())
+	}
+}
+
+func TestSubscribesAndReceivesLiveCommittedBatchesWithOperationType(t *testing.T) {
+	db, directory := initKeyValueDb()
+	defer os.RemoveAll(directory)
+
+	subscription := db.Subscribe(1)
+	defer subscription.Close()
+
+	txn := db.newTransaction()
+	_ = txn.Put(model.NewSlice([]byte("Company")), model.NewSlice([]byte("TW")))
+	_ = txn.PutWithTTL(model.NewSlice([]byte("Session")), model.NewSlice([]byte("token")), time.Hour)
+	_ = txn.Commit()
+
+	batch := receiveBatch(t, subscription)
+	if batch.Sequence != 1 {
+		t.Fatalf("Expected batch sequence to be %v, received %v", 1, batch.Sequence)
+	}
+	if batch.Changes[0].Operation != OperationPut {
+		t.Fatalf("Expected operation of first change to be %v, received %v", OperationPut, batch.Changes[0].Operation)
+	}
+	if batch.Changes[1].Operation != OperationPutWithTTL {
+		t.Fatalf("Expected operation of second change to be %v, received %v", OperationPutWithTTL, batch.Changes[1].Operation)
+	}
+}
+
+func TestResumesASubscriptionFromItsCursor(t *testing.T) {
+	db, directory := initKeyValueDb()
+	defer os.RemoveAll(directory)
+
+	commitKeyValue(db, "Company", "TW")
+	commitKeyValue(db, "Field", "Storage engine")
+
+	subscription := db.Subscribe(1)
+	receiveBatch(t, subscription)
+	subscription.Close()
+
+	resumed := db.Subscribe(subscription.Cursor())
+	defer resumed.Close()
+
+	if batch := receiveBatch(t, resumed); batch.Sequence != 2 {
+		t.Fatalf("Expected resumed subscription to receive sequence %v, received %v", 2, batch.Sequence)
+	}
+}
+
+func TestASlowSubscriberReceivesAllCommittedBatchesInOrder(t *testing.T) {
+	db, directory := initKeyValueDb()
+	defer os.RemoveAll(directory)
+
+	subscription := db.Subscribe(1)
+	defer subscription.Close()
+
+	totalBatches := 3 * liveBatchesCapacity
+	for count := 1; count <= totalBatches; count++ {
+		commitKeyValue(db, "Key-"+strconv.Itoa(count), "Value-"+strconv.Itoa(count))
+	}
+	for count := 1; count <= totalBatches; count++ {
+		batch := receiveBatch(t, subscription)
+		if batch.Sequence != uint64(count) {
+			t.Fatalf("Expected batch sequence to be %v, received %v", count, batch.Sequence)
+		}
+	}
+}
+
+func TestReceivesTheBatchesCommittedWhileASubscriptionReadsTheWALExactlyOnceInOrder(t *testing.T) {
+	db, directory := initKeyValueDb()
+	defer os.RemoveAll(directory)
+
+	for count := 1; count <= 200; count++ {
+		commitKeyValue(db, "Key-"+strconv.Itoa(count), "Value-"+strconv.Itoa(count))
+	}
+	subscription := db.Subscribe(1)
+	defer subscription.Close()
+
+	committed := make(chan bool)
+	go func() {
+		for count := 201; count <= 300; count++ {
+			commitKeyValue(db, "Key-"+strconv.Itoa(count), "Value-"+strconv.Itoa(count))
+		}
+		close(committed)
+	}()
+	for count := 1; count <= 300; count++ {
+		batch := receiveBatch(t, subscription)
+		if batch.Sequence != uint64(count) || batch.Changes[0].KeyValuePair.Key.AsString() != "Key-"+strconv.Itoa(count) {
+			t.Fatalf("Expected batch sequence %v with key %v, received %v with key %v", count, "Key-"+strconv.Itoa(count), batch.Sequence, batch.Changes[0].KeyValuePair.Key.AsString())
+		}
+	}
+	<-committed
+}
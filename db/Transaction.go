@@ -1,9 +1,11 @@
 package db
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"storage-engine-workshop/db/model"
+	"time"
 )
 
 type Transaction struct {
@@ -40,17 +42,81 @@ func (txn *Transaction) Put(key, value model.Slice) error {
 	return nil
 }
 
+func (txn *Transaction) PutWithTTL(key, value model.Slice, ttl time.Duration) error {
+	if ttl <= 0 {
+		return errors.New(fmt.Sprintf("ttl must be greater than zero, received %v", ttl))
+	}
+	if txn.batch.isTotalSizeGreaterThan(maxSizeAllowedBytes) {
+		return errors.New(fmt.Sprintf("can not add more than the total key/value pair size %v in a transaction", maxSizeAllowedBytes))
+	}
+	txn.batch.addWithExpiry(key, value, model.ExpiresAfter(ttl))
+	return nil
+}
+
 func (txn *Transaction) Commit() error {
+	return txn.CommitContext(context.Background())
+}
+
+// CommitContext returns ctx.Err() if ctx is done before the batch is committed. A batch that the executor has already
+// dequeued is still committed, even if the caller stops waiting for it.
+func (txn *Transaction) CommitContext(ctx context.Context) error {
 	if txn.batch.isEmpty() {
 		return errors.New("nothing to commit, put key/value before committing")
 	}
-	return <-txn.executor.put(txn.batch)
+	select {
+	case err := <-txn.executor.putContext(ctx, txn.batch):
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (txn ReadonlyTransaction) Get(key model.Slice) model.GetResult {
-	return <-txn.executor.get(key)
+	return txn.executor.get(key)
+}
+
+func (txn ReadonlyTransaction) GetContext(ctx context.Context, key model.Slice) (model.GetResult, error) {
+	if err := ctx.Err(); err != nil {
+		return model.GetResult{Key: key, Value: model.NilSlice(), Exists: false}, err
+	}
+	return txn.executor.get(key), nil
 }
 
 func (txn ReadonlyTransaction) MultiGet(keys []model.Slice) []model.GetResult {
-	return <-txn.executor.multiGet(keys)
+	return txn.executor.multiGet(keys)
+}
+
+func (txn ReadonlyTransaction) MultiGetContext(ctx context.Context, keys []model.Slice) ([]model.GetResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return txn.executor.multiGet(keys), nil
+}
+
+func (txn ReadonlyTransaction) NewIterator(lowerBound, upperBound model.Slice) *Iterator {
+	return txn.NewIteratorWithOptions(lowerBound, upperBound, ReadOptions{})
+}
+
+func (txn ReadonlyTransaction) NewIteratorWithOptions(lowerBound, upperBound model.Slice, readOptions ReadOptions) *Iterator {
+	return txn.executor.newIterator(lowerBound, upperBound, model.NilSlice(), readOptions)
+}
+
+// ScanPrefix iterates over the keys starting with the prefix, it relies on the key comparator ordering keys bytewise.
+func (txn ReadonlyTransaction) ScanPrefix(prefix model.Slice) *Iterator {
+	return txn.executor.newIterator(prefix, prefixSuccessor(prefix), prefix, ReadOptions{})
+}
+
+// prefixSuccessor returns the smallest key greater than all the keys starting with the prefix,
+// a prefix made of 0xff bytes has no successor and leaves the upper bound open.
+func prefixSuccessor(prefix model.Slice) model.Slice {
+	contents := prefix.GetRawContent()
+	for index := len(contents) - 1; index >= 0; index-- {
+		if contents[index] != 0xff {
+			successor := make([]byte, index+1)
+			copy(successor, contents[:index+1])
+			successor[index] = successor[index] + 1
+			return model.NewSlice(successor)
+		}
+	}
+	return model.NilSlice()
 }
@@ -1,11 +1,13 @@
 package db
 
 import (
+	"context"
 	"os"
 	"storage-engine-workshop/db/model"
 	"strconv"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestAttemptsToCommitATransactionWithEmptyBatch(t *testing.T) {
@@ -70,3 +72,159 @@ func TestPutsMultipleKeyValuePairsInDifferentGoroutines(t *testing.T) {
 		}
 	}
 }
+
+func TestAttemptsToPutAKeyValuePairWithNonPositiveTTL(t *testing.T) {
+	executor, directory := initRequestExecutor()
+	defer os.RemoveAll(directory)
+
+	transaction := newTransaction(executor)
+
+	if err := transaction.PutWithTTL(model.NewSlice([]byte("Key")), model.NewSlice([]byte("Value")), 0); err == nil {
+		t.Fatalf("Expected an error on putting with zero ttl but received no error")
+	}
+}
+
+func TestPutsAKeyValuePairWithTTLAndGetsByKeyBeforeExpiry(t *testing.T) {
+	executor, directory := initRequestExecutor()
+	defer os.RemoveAll(directory)
+
+	transaction := newTransaction(executor)
+
+	_ = transaction.PutWithTTL(model.NewSlice([]byte("Key")), model.NewSlice([]byte("Value")), time.Hour)
+	_ = transaction.Commit()
+
+	readonlyTxn := newReadonlyTransaction(executor)
+	if getResult := readonlyTxn.Get(model.NewSlice([]byte("Key"))); getResult.Value.AsString() != "Value" {
+		t.Fatalf("Expected %v, received %v", "Value", getResult.Value.AsString())
+	}
+}
+
+func TestPutsAKeyValuePairWithTTLAgainAfterExpiryAndGetsTheNewValue(t *testing.T) {
+	executor, directory := initRequestExecutor()
+	defer os.RemoveAll(directory)
+
+	transaction := newTransaction(executor)
+	_ = transaction.PutWithTTL(model.NewSlice([]byte("Session")), model.NewSlice([]byte("Token-1")), 20*time.Millisecond)
+	if err := transaction.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	transaction = newTransaction(executor)
+	_ = transaction.PutWithTTL(model.NewSlice([]byte("Session")), model.NewSlice([]byte("Token-2")), time.Hour)
+	if err := transaction.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	readonlyTxn := newReadonlyTransaction(executor)
+	if getResult := readonlyTxn.Get(model.NewSlice([]byte("Session"))); getResult.Value.AsString() != "Token-2" {
+		t.Fatalf("Expected %v, received %v", "Token-2", getResult.Value.AsString())
+	}
+}
+
+func TestPutsAKeyValuePairWithTTLAndDoesNotGetItAfterExpiry(t *testing.T) {
+	executor, directory := initRequestExecutor()
+	defer os.RemoveAll(directory)
+
+	transaction := newTransaction(executor)
+
+	_ = transaction.PutWithTTL(model.NewSlice([]byte("Key")), model.NewSlice([]byte("Value")), 10*time.Millisecond)
+	_ = transaction.Put(model.NewSlice([]byte("Other")), model.NewSlice([]byte("Value")))
+	_ = transaction.Commit()
+
+	time.Sleep(20 * time.Millisecond)
+
+	readonlyTxn := newReadonlyTransaction(executor)
+	if getResult := readonlyTxn.Get(model.NewSlice([]byte("Key"))); getResult.Exists {
+		t.Fatalf("Expected key %v to be expired, but was present with value %v", "Key", getResult.Value.AsString())
+	}
+	multiGetResult := readonlyTxn.MultiGet([]model.Slice{model.NewSlice([]byte("Key")), model.NewSlice([]byte("Other"))})
+	for _, getResult := range multiGetResult {
+		if getResult.Exists && getResult.Key.AsString() == "Key" {
+			t.Fatalf("Expected key %v to be expired in multiGet, but was present", "Key")
+		}
+	}
+}
+
+func TestCommitsATransactionWithContext(t *testing.T) {
+	executor, directory := initRequestExecutor()
+	defer os.RemoveAll(directory)
+
+	transaction := newTransaction(executor)
+	_ = transaction.Put(model.NewSlice([]byte("Key")), model.NewSlice([]byte("Value")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := transaction.CommitContext(ctx); err != nil {
+		t.Fatalf("Expected no error while committing with context, received %v", err)
+	}
+	getResult, err := newReadonlyTransaction(executor).GetContext(ctx, model.NewSlice([]byte("Key")))
+	if err != nil || getResult.Value.AsString() != "Value" {
+		t.Fatalf("Expected %v, received %v with error %v", "Value", getResult.Value.AsString(), err)
+	}
+}
+
+func TestDoesNotCommitATransactionWithCancelledContext(t *testing.T) {
+	executor, directory := initRequestExecutor()
+	defer os.RemoveAll(directory)
+
+	transaction := newTransaction(executor)
+	_ = transaction.Put(model.NewSlice([]byte("Key")), model.NewSlice([]byte("Value")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := transaction.CommitContext(ctx); err != context.Canceled {
+		t.Fatalf("Expected error %v, received %v", context.Canceled, err)
+	}
+	if getResult := newReadonlyTransaction(executor).Get(model.NewSlice([]byte("Key"))); getResult.Exists {
+		t.Fatalf("Expected key %v to not be committed, but was present with value %v", "Key", getResult.Value.AsString())
+	}
+}
+
+func TestReturnsOnDeadlineWhileCommittingToAStuckExecutor(t *testing.T) {
+	stuckExecutor := &RequestExecutor{requestChannel: make(chan interface{})}
+
+	transaction := newTransaction(stuckExecutor)
+	_ = transaction.Put(model.NewSlice([]byte("Key")), model.NewSlice([]byte("Value")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := transaction.CommitContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Expected error %v, received %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestSkipsAPutRequestWhoseContextIsCancelledBeforeItIsDequeued(t *testing.T) {
+	executor, directory := initRequestExecutor()
+	defer os.RemoveAll(directory)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	batch := NewBatch()
+	batch.add(model.NewSlice([]byte("Key")), model.NewSlice([]byte("Value")))
+	responseChannel := make(chan error, 1)
+	executor.requestChannel <- PutRequest{Context: ctx, Batch: batch, ResponseChannel: responseChannel}
+
+	if err := <-responseChannel; err != context.Canceled {
+		t.Fatalf("Expected error %v, received %v", context.Canceled, err)
+	}
+	if getResult := executor.get(model.NewSlice([]byte("Key"))); getResult.Exists {
+		t.Fatalf("Expected key %v to be skipped, but was present with value %v", "Key", getResult.Value.AsString())
+	}
+}
+
+func TestReturnsAnErrorForMultiGetWithCancelledContext(t *testing.T) {
+	executor, directory := initRequestExecutor()
+	defer os.RemoveAll(directory)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := newReadonlyTransaction(executor).MultiGetContext(ctx, []model.Slice{model.NewSlice([]byte("Key"))}); err != context.Canceled {
+		t.Fatalf("Expected error %v, received %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"storage-engine-workshop/storage/iterator"
+	"storage-engine-workshop/storage/memory"
+	"storage-engine-workshop/storage/sst"
+	"sync/atomic"
+	"time"
+)
+
+// Version is an immutable view of the sources a read consults: the active memTable, the immutable memTables (newest first)
+// and a snapshot of the ssTables. Readers acquire the current version and release it once they are done,
+// the workspace installs a new version whenever a memTable is swapped or flushed, or ssTables are compacted.
+// Every version owns its snapshot, which keeps the ssTables of the version from being deleted by compaction.
+// Only the active memTable keeps changing under a version, a reader may observe a batch that is still being applied to it.
+type Version struct {
+	activeMemTable     *memory.MemTable
+	immutableMemTables []*memory.MemTable
+	ssTables           *sst.Snapshot
+	references         int32
+}
+
+func newVersion(activeMemTable *memory.MemTable, immutableMemTables []*memory.MemTable, ssTables *sst.Snapshot) *Version {
+	return &Version{
+		activeMemTable:     activeMemTable,
+		immutableMemTables: immutableMemTables,
+		ssTables:           ssTables,
+		references:         1,
+	}
+}
+
+func (version *Version) acquire() {
+	atomic.AddInt32(&version.references, 1)
+}
+
+// release releases the snapshot of the ssTables along with the last reference, letting compacted ssTables be deleted.
+func (version *Version) release() {
+	if atomic.AddInt32(&version.references, -1) == 0 {
+		version.ssTables.Release()
+	}
+}
+
+func (version *Version) referenceCount() int32 {
+	return atomic.LoadInt32(&version.references)
+}
+
+func (version *Version) memTables() []*memory.MemTable {
+	return append([]*memory.MemTable{version.activeMemTable}, version.immutableMemTables...)
+}
+
+func (version *Version) get(key model.Slice, keyComparator comparator.KeyComparator) model.GetResult {
+	now := time.Now()
+	for _, memTable := range version.memTables() {
+		if getResult := memTable.Get(key); getResult.Exists {
+			return missingIfExpired(getResult, now)
+		}
+	}
+	return missingIfExpired(version.ssTables.Get(key, keyComparator), now)
+}
+
+func (version *Version) multiGet(keys []model.Slice, keyComparator comparator.KeyComparator) []model.GetResult {
+	now := time.Now()
+	allGetResults := make([]model.GetResult, len(keys))
+
+	getFromMemTables := func(key model.Slice) (model.GetResult, bool) {
+		for _, memTable := range version.memTables() {
+			if getResult := memTable.Get(key); getResult.Exists {
+				return getResult, true
+			}
+		}
+		return model.GetResult{}, false
+	}
+
+	var missingIndices []int
+	var missingKeys []model.Slice
+	for index, key := range keys {
+		if getResult, ok := getFromMemTables(key); ok {
+			allGetResults[index] = missingIfExpired(getResult, now)
+		} else {
+			missingIndices = append(missingIndices, index)
+			missingKeys = append(missingKeys, key)
+		}
+	}
+	if len(missingKeys) > 0 {
+		getResults := version.ssTables.MultiGet(missingKeys, keyComparator).Values
+		for index, getResult := range getResults {
+			allGetResults[missingIndices[index]] = missingIfExpired(getResult, now)
+		}
+	}
+	return allGetResults
+}
+
+func (version *Version) newIterators(lowerBound, upperBound, prefix model.Slice, keyComparator comparator.KeyComparator, readOptions ReadOptions) []iterator.Iterator {
+	ssTableReadOptions := readOptions.ssTableReadOptions(lowerBound, upperBound)
+	var iterators []iterator.Iterator
+	for _, memTable := range version.memTables() {
+		iterators = append(iterators, memTable.NewIterator())
+	}
+	if prefix.Size() > 0 {
+		return append(iterators, version.ssTables.NewPrefixIterators(prefix, keyComparator, ssTableReadOptions)...)
+	}
+	return append(iterators, version.ssTables.NewIterators(keyComparator, ssTableReadOptions)...)
+}
+
+func missingIfExpired(getResult model.GetResult, now time.Time) model.GetResult {
+	if getResult.IsExpiredAt(now) {
+		return model.GetResult{Key: getResult.Key, Value: model.NilSlice(), Exists: false}
+	}
+	return getResult
+}
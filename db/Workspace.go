@@ -1,18 +1,32 @@
 package db
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"storage-engine-workshop/db/model"
 	"storage-engine-workshop/log"
 	"storage-engine-workshop/storage"
 	"storage-engine-workshop/storage/memory"
 	"storage-engine-workshop/storage/sst"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// Workspace serialises writes through the request executor, reads acquire the current version and run on the goroutine of the caller.
 type Workspace struct {
 	wal              *log.WAL
 	ssTables         *sst.SSTables
-	activeMemTable   *memory.MemTable
-	inactiveMemTable *memory.MemTable
+	current          *Version
+	versionLock      sync.RWMutex
+	scheduler        *storage.Scheduler
+	writeStalls      *writeStalls
+	compactionQueued int32
+	compactionTimer  *time.Timer
+	timerLock        sync.Mutex
+	flushError       error
+	flushErrorLock   sync.Mutex
 	configuration    Configuration
 }
 
@@ -21,37 +35,59 @@ func newWorkSpace(configuration Configuration) (*Workspace, error) {
 	if err != nil {
 		return nil, err
 	}
-	ssTables, err := sst.NewSSTables(configuration.directory)
+	ssTables, err := sst.NewSSTables(configuration.directory, configuration.ssTableOptions())
 	if err != nil {
 		return nil, err
 	}
-	return &Workspace{
-		wal:            wal,
-		ssTables:       ssTables,
-		activeMemTable: memory.NewMemTable(32, configuration.keyComparator),
-		configuration:  configuration,
-	}, nil
+	workspace := &Workspace{
+		wal:           wal,
+		ssTables:      ssTables,
+		current:       newVersion(memory.NewMemTable(32, configuration.keyComparator), nil, ssTables.Snapshot()),
+		scheduler:     storage.NewScheduler(configuration.backgroundWorkers),
+		writeStalls:   newWriteStalls(configuration.writeStalls),
+		configuration: configuration,
+	}
+	workspace.scheduleDueCompaction()
+	return workspace, nil
 }
 
 func (workspace *Workspace) put(batch *Batch) error {
-	writeToSSTable := func() {
-		//handle error
-		storage.NewMemTableWriter(workspace.activeMemTable, workspace.ssTables).Write()
+	writeToSSTable := func(memTable *memory.MemTable) {
+		writeStatus := storage.NewScheduledMemTableWriter(memTable, workspace.ssTables, workspace.scheduler).Write()
+		go func() {
+			if status := <-writeStatus; status.IsSuccess() {
+				workspace.dropFlushed(memTable)
+				workspace.scheduleCompaction()
+			} else {
+				workspace.failFlush(status.Err())
+			}
+		}()
 	}
 	mayBeSwapMemTable := func() {
-		if workspace.activeMemTable.TotalSize() >= workspace.configuration.bufferSizeBytes {
-			writeToSSTable()
-			workspace.inactiveMemTable = workspace.activeMemTable
-			workspace.activeMemTable = memory.NewMemTable(32, workspace.configuration.keyComparator)
+		activeMemTable := workspace.activeMemTable()
+		if activeMemTable.TotalSize() >= workspace.configuration.bufferSizeBytes {
+			workspace.installVersion(func(current *Version) *Version {
+				immutableMemTables := append([]*memory.MemTable{current.activeMemTable}, current.immutableMemTables...)
+				return newVersion(memory.NewMemTable(32, workspace.configuration.keyComparator), immutableMemTables, workspace.ssTables.Snapshot())
+			})
+			writeToSSTable(activeMemTable)
 		}
 	}
 	putInMemTable := func() {
 		for _, keyValuePair := range batch.keyValuePairs {
 			mayBeSwapMemTable()
-			workspace.activeMemTable.Put(keyValuePair.Key, keyValuePair.Value)
+			activeMemTable := workspace.activeMemTable()
+			activeMemTable.PutWithExpiry(keyValuePair.Key, keyValuePair.Value, keyValuePair.ExpiresAt)
+			activeMemTable.RecordSequence(workspace.wal.LastSequence())
 		}
 	}
 	write := func() error {
+		if err := workspace.failedFlush(); err != nil {
+			return err
+		}
+		if err := workspace.writeStalls.stall(workspace.pendingWork, workspace.failedFlush); err != nil {
+			return err
+		}
 		if err := workspace.wal.BeginTransactionHeader(batch.totalSize()); err != nil {
 			return err
 		}
@@ -65,49 +101,177 @@ func (workspace *Workspace) put(batch *Batch) error {
 }
 
 func (workspace *Workspace) get(key model.Slice) model.GetResult {
-	memTables := []*memory.MemTable{workspace.activeMemTable, workspace.inactiveMemTable}
-	get := func(memTable *memory.MemTable) model.GetResult {
-		return memTable.Get(key)
+	version := workspace.acquireVersion()
+	defer version.release()
+
+	return version.get(key, workspace.configuration.keyComparator)
+}
+
+func (workspace *Workspace) multiGet(keys []model.Slice) []model.GetResult {
+	version := workspace.acquireVersion()
+	defer version.release()
+
+	return version.multiGet(keys, workspace.configuration.keyComparator)
+}
+
+// newIterator hands the acquired version over to the iterator which releases it on Close.
+func (workspace *Workspace) newIterator(lowerBound, upperBound, prefix model.Slice, readOptions ReadOptions) *Iterator {
+	version := workspace.acquireVersion()
+	sources := version.newIterators(lowerBound, upperBound, prefix, workspace.configuration.keyComparator, readOptions)
+	return newIterator(version, sources, lowerBound, upperBound, workspace.configuration.keyComparator)
+}
+
+// scheduleCompaction queues a compaction unless one is queued already, a compaction which merges ssTables installs a version
+// and queues the next compaction till there is nothing to merge.
+func (workspace *Workspace) scheduleCompaction() {
+	if !atomic.CompareAndSwapInt32(&workspace.compactionQueued, 0, 1) {
+		return
 	}
-	for _, memTable := range memTables {
-		if memTable != nil {
-			if getResult := get(memTable); getResult.Exists {
-				return getResult
-			}
+	workspace.scheduler.ScheduleCompaction(func() {
+		atomic.StoreInt32(&workspace.compactionQueued, 0)
+		compacted, err := workspace.ssTables.Compact(workspace.configuration.keyComparator)
+		if err != nil {
+			workspace.writeStalls.resume()
+			return
 		}
+		if !compacted {
+			workspace.scheduleDueCompaction()
+			return
+		}
+		workspace.installVersion(func(current *Version) *Version {
+			return newVersion(current.activeMemTable, current.immutableMemTables, workspace.ssTables.Snapshot())
+		})
+		workspace.scheduleCompaction()
+	})
+}
+
+// scheduleDueCompaction queues a compaction for the time the compaction style falls due as the ssTables age,
+// so that FIFO compaction drops the ssTables older than MaxAge even once the writes stop.
+func (workspace *Workspace) scheduleDueCompaction() {
+	dueAt, ok := workspace.ssTables.NextCompactionDueAt()
+
+	workspace.timerLock.Lock()
+	defer workspace.timerLock.Unlock()
+	if workspace.compactionTimer != nil {
+		workspace.compactionTimer.Stop()
+		workspace.compactionTimer = nil
+	}
+	if ok {
+		workspace.compactionTimer = time.AfterFunc(time.Until(dueAt), workspace.scheduleCompaction)
 	}
-	return workspace.ssTables.Get(key, workspace.configuration.keyComparator)
 }
 
-func (workspace *Workspace) multiGet(keys []model.Slice) []model.GetResult {
-	index, allGetResults := 0, make([]model.GetResult, len(keys))
+// compactRange installs a version once the range is compacted, even if ctx is done before the whole range is compacted.
+func (workspace *Workspace) compactRange(ctx context.Context, start, end model.Slice) (CompactRangeStatistics, error) {
+	statistics, err := workspace.ssTables.CompactRange(ctx, start, end, workspace.configuration.keyComparator)
+	workspace.installVersion(func(current *Version) *Version {
+		return newVersion(current.activeMemTable, current.immutableMemTables, workspace.ssTables.Snapshot())
+	})
+	return CompactRangeStatistics{BytesRead: statistics.BytesRead, BytesWritten: statistics.BytesWritten}, err
+}
 
-	buildResult := func(multiGetResult model.MultiGetResult) {
-		for _, getResult := range multiGetResult.Values {
-			if getResult.Exists {
-				allGetResults[index] = getResult
-				index = index + 1
-			}
-		}
+func (workspace *Workspace) statistics() Statistics {
+	blockCacheStatistics := workspace.ssTables.BlockCacheStatistics()
+	currentWriteStall, writeStalls := workspace.writeStalls.snapshot()
+	return Statistics{
+		BlockCacheHits:    blockCacheStatistics.Hits,
+		BlockCacheMisses:  blockCacheStatistics.Misses,
+		CurrentWriteStall: currentWriteStall,
+		WriteStalls:       writeStalls,
 	}
-	multiGetIn := func(memTable *memory.MemTable, keys []model.Slice) []model.Slice {
-		if memTable != nil {
-			multiGetResult, missingKeys := workspace.activeMemTable.MultiGet(keys)
-			buildResult(multiGetResult)
-			return missingKeys
+}
+
+// pendingWork answers the number of immutable memTables waiting to be flushed and the number of ssTables waiting for a compaction,
+// as measured by the compaction style.
+func (workspace *Workspace) pendingWork() (int, int) {
+	workspace.versionLock.RLock()
+	immutableMemTables := len(workspace.current.immutableMemTables)
+	workspace.versionLock.RUnlock()
+
+	return immutableMemTables, workspace.ssTables.CompactionBacklog()
+}
+
+func (workspace *Workspace) pauseCompactions() {
+	workspace.scheduler.PauseCompactions()
+}
+
+// resumeCompactions wakes up the commits blocked by a write stall, they wait again if the pending work is still at a stop threshold.
+func (workspace *Workspace) resumeCompactions() {
+	workspace.scheduler.ResumeCompactions()
+	workspace.writeStalls.resume()
+}
+
+func (workspace *Workspace) acquireVersion() *Version {
+	workspace.versionLock.RLock()
+	defer workspace.versionLock.RUnlock()
+
+	workspace.current.acquire()
+	return workspace.current
+}
+
+func (workspace *Workspace) activeMemTable() *memory.MemTable {
+	workspace.versionLock.RLock()
+	defer workspace.versionLock.RUnlock()
+
+	return workspace.current.activeMemTable
+}
+
+// installVersion wakes up the commits blocked by a write stall, the new version may have brought the pending work below the stop thresholds.
+func (workspace *Workspace) installVersion(next func(current *Version) *Version) {
+	workspace.versionLock.Lock()
+	previous := workspace.current
+	workspace.current = next(previous)
+	workspace.versionLock.Unlock()
+
+	previous.release()
+	workspace.writeStalls.resume()
+}
+
+func (workspace *Workspace) dropFlushed(flushedMemTable *memory.MemTable) {
+	workspace.installVersion(func(current *Version) *Version {
+		var immutableMemTables []*memory.MemTable
+		for _, memTable := range current.immutableMemTables {
+			if memTable != flushedMemTable {
+				immutableMemTables = append(immutableMemTables, memTable)
+			}
 		}
-		return []model.Slice{}
+		return newVersion(current.activeMemTable, immutableMemTables, workspace.ssTables.Snapshot())
+	})
+}
+
+// failFlush rejects every commit from now on, the memTable which could not be flushed stays immutable and keeps its key/value pairs readable.
+func (workspace *Workspace) failFlush(err error) {
+	workspace.flushErrorLock.Lock()
+	if workspace.flushError == nil {
+		workspace.flushError = errors.New(fmt.Sprintf("a memTable could not be flushed, the db rejects commits, %v", err))
 	}
+	workspace.flushErrorLock.Unlock()
 
-	missingKeys := multiGetIn(workspace.activeMemTable, keys)
-	missingKeys = multiGetIn(workspace.inactiveMemTable, missingKeys)
+	workspace.writeStalls.resume()
+}
 
-	if len(missingKeys) > 0 {
-		getResults := workspace.ssTables.MultiGet(missingKeys, workspace.configuration.keyComparator).Values
-		for _, getResult := range getResults {
-			allGetResults[index] = getResult
-			index = index + 1
-		}
+func (workspace *Workspace) failedFlush() error {
+	workspace.flushErrorLock.Lock()
+	defer workspace.flushErrorLock.Unlock()
+
+	return workspace.flushError
+}
+
+func (workspace *Workspace) close() {
+	workspace.scheduler.Stop()
+	workspace.timerLock.Lock()
+	if workspace.compactionTimer != nil {
+		workspace.compactionTimer.Stop()
 	}
-	return allGetResults
+	workspace.timerLock.Unlock()
+	workspace.wal.Close()
+}
+
+func (workspace *Workspace) lastSequence() uint64 {
+	return workspace.wal.LastSequence()
+}
+
+// walReader runs on the request goroutine, between two commits.
+func (workspace *Workspace) walReader() *log.Reader {
+	return workspace.wal.NewReader()
 }
@@ -1,11 +1,15 @@
 package db
 
 import (
+	"io/ioutil"
 	"os"
+	"path"
 	"storage-engine-workshop/db/model"
 	"storage-engine-workshop/storage/comparator"
+	"storage-engine-workshop/storage/sst"
 	"strconv"
 	"testing"
+	"time"
 )
 
 func TestPut200KeysValuesAndGetByKeysInWorkspace(t *testing.T) {
@@ -61,7 +65,7 @@ func TestPut1000KeysValuesAndMultiGetKeysInWorkspace(t *testing.T) {
 	workspace, _ := newWorkSpace(configuration)
 
 	batch := NewBatch()
-	for count := 1; count <= 200; count++ {
+	for count := 1; count <= 1000; count++ {
 		batch.add(keyUsing(count), valueUsing(count))
 	}
 	_ = workspace.put(batch)
@@ -90,3 +94,255 @@ func TestPut1000KeysValuesAndMultiGetKeysInWorkspace(t *testing.T) {
 		}
 	}
 }
+
+func TestMultiGetsKeysFromActiveAndInactiveMemTablesAndSSTablesInTheOrderOfKeys(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 32
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{})
+	workspace, _ := newWorkSpace(configuration)
+
+	putKeyValue := func(key, value string) {
+		batch := NewBatch()
+		batch.add(model.NewSlice([]byte(key)), model.NewSlice([]byte(value)))
+		_ = workspace.put(batch)
+	}
+	putKeyValue("SSTable-Key", "Value-In-SSTable")
+	putKeyValue("SSTable-Key-Other", "Value-In-SSTable-Other")
+	putKeyValue("Inactive-Key", "Value-In-Inactive-MemTable")
+	putKeyValue("Active-Key", "Value-In-Active-MemTable")
+	allowFlushingSSTable()
+
+	keys := []model.Slice{
+		model.NewSlice([]byte("Unknown")),
+		model.NewSlice([]byte("SSTable-Key")),
+		model.NewSlice([]byte("Inactive-Key")),
+		model.NewSlice([]byte("Active-Key")),
+	}
+	expectedValues := []string{"", "Value-In-SSTable", "Value-In-Inactive-MemTable", "Value-In-Active-MemTable"}
+
+	multiGetResult := workspace.multiGet(keys)
+	if len(multiGetResult) != len(keys) {
+		t.Fatalf("Expected %v results, received %v", len(keys), len(multiGetResult))
+	}
+	for index, result := range multiGetResult {
+		if result.Key.AsString() != keys[index].AsString() {
+			t.Fatalf("Expected key to be %v, received %v", keys[index].AsString(), result.Key.AsString())
+		}
+		if result.Value.AsString() != expectedValues[index] {
+			t.Fatalf("Expected value to be %v, received %v", expectedValues[index], result.Value.AsString())
+		}
+	}
+	if multiGetResult[0].Exists {
+		t.Fatalf("Expected key %v to be missing", "Unknown")
+	}
+}
+
+func TestDropsFlushedMemTablesFromTheCurrentVersion(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 32
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{})
+	workspace, _ := newWorkSpace(configuration)
+
+	batch := NewBatch()
+	for count := 1; count <= 20; count++ {
+		batch.add(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+	}
+	_ = workspace.put(batch)
+	allowFlushingSSTable()
+
+	version := workspace.acquireVersion()
+	defer version.release()
+
+	if len(version.immutableMemTables) != 0 {
+		t.Fatalf("Expected flushed memTables to be dropped from the current version, found %v immutable memTables", len(version.immutableMemTables))
+	}
+	if getResult := workspace.get(model.NewSlice([]byte("Key-1"))); getResult.Value.AsString() != "Value-1" {
+		t.Fatalf("Expected value to be %v, received %v", "Value-1", getResult.Value.AsString())
+	}
+}
+
+func TestIteratorHoldsTheVersionUntilClosed(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 1024
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{})
+	workspace, _ := newWorkSpace(configuration)
+
+	version := workspace.current
+	iterator := workspace.newIterator(model.NilSlice(), model.NilSlice(), model.NilSlice(), ReadOptions{})
+	if version.referenceCount() != 2 {
+		t.Fatalf("Expected the version to be referenced by the workspace and the iterator, found %v references", version.referenceCount())
+	}
+	iterator.Close()
+	if version.referenceCount() != 1 {
+		t.Fatalf("Expected the version to be referenced only by the workspace, found %v references", version.referenceCount())
+	}
+}
+
+func TestCountsBlockCacheHitsAndMissesInStatistics(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 32
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{}).WithBlockCacheCapacity(1024 * 1024)
+	workspace, _ := newWorkSpace(configuration)
+
+	batch := NewBatch()
+	for count := 1; count <= 20; count++ {
+		batch.add(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+	}
+	_ = workspace.put(batch)
+	allowFlushingSSTable()
+
+	before := workspace.statistics()
+	for attempt := 0; attempt < 2; attempt++ {
+		if getResult := workspace.get(model.NewSlice([]byte("Key-1"))); getResult.Value.AsString() != "Value-1" {
+			t.Fatalf("Expected value to be %v, received %v", "Value-1", getResult.Value.AsString())
+		}
+	}
+	statistics := workspace.statistics()
+	if misses, hits := statistics.BlockCacheMisses-before.BlockCacheMisses, statistics.BlockCacheHits-before.BlockCacheHits; misses != 1 || hits != 1 {
+		t.Fatalf("Expected 1 miss and 1 hit, received %v misses and %v hits", misses, hits)
+	}
+}
+
+func TestCompactsSSTablesAfterFlushesAndGetsEveryKey(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 32
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{})
+	workspace, _ := newWorkSpace(configuration)
+
+	for count := 1; count <= 40; count++ {
+		batch := NewBatch()
+		batch.add(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+		_ = workspace.put(batch)
+	}
+	allowFlushingSSTable()
+
+	ssTableFiles, _ := ioutil.ReadDir(path.Join(directory, "sst"))
+	if len(ssTableFiles) >= 20 {
+		t.Fatalf("Expected flushed ssTables to be compacted, found %v ssTable files", len(ssTableFiles))
+	}
+	for count := 1; count <= 40; count++ {
+		if getResult := workspace.get(model.NewSlice([]byte("Key-" + strconv.Itoa(count)))); getResult.Value.AsString() != "Value-"+strconv.Itoa(count) {
+			t.Fatalf("Expected value to be %v, received %v", "Value-"+strconv.Itoa(count), getResult.Value.AsString())
+		}
+	}
+}
+
+func TestCompactsSSTablesIntoLevelsAndGetsTheNewestVersionOfEveryKey(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 32
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{}).
+		WithLeveledCompaction(sst.LeveledCompactionOptions{Level0FileTrigger: 2, MaxBytesForLevelBase: 512, LevelSizeMultiplier: 2, TargetFileSize: 64})
+	workspace, _ := newWorkSpace(configuration)
+
+	for count := 1; count <= 60; count++ {
+		batch := NewBatch()
+		batch.add(model.NewSlice([]byte("Key-"+strconv.Itoa(count%15))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+		_ = workspace.put(batch)
+	}
+	allowFlushingSSTable()
+
+	for count := 46; count <= 60; count++ {
+		if getResult := workspace.get(model.NewSlice([]byte("Key-" + strconv.Itoa(count%15)))); getResult.Value.AsString() != "Value-"+strconv.Itoa(count) {
+			t.Fatalf("Expected value to be %v, received %v", "Value-"+strconv.Itoa(count), getResult.Value.AsString())
+		}
+	}
+}
+
+func TestDropsTheOldestSSTablesWithFIFOCompaction(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 32
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{}).
+		WithFIFOCompaction(sst.FIFOCompactionOptions{MaxTotalSize: 1024})
+	workspace, _ := newWorkSpace(configuration)
+
+	for count := 1; count <= 40; count++ {
+		batch := NewBatch()
+		batch.add(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+		_ = workspace.put(batch)
+	}
+	allowFlushingSSTable()
+
+	if getResult := workspace.get(model.NewSlice([]byte("Key-1"))); getResult.Exists {
+		t.Fatalf("Expected key %v of a dropped SSTable to be missing", "Key-1")
+	}
+	if getResult := workspace.get(model.NewSlice([]byte("Key-40"))); getResult.Value.AsString() != "Value-40" {
+		t.Fatalf("Expected value to be %v, received %v", "Value-40", getResult.Value.AsString())
+	}
+}
+
+func TestDropsSSTablesOlderThanTheMaxAgeWithFIFOCompactionOnceTheWritesStop(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 32
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{}).
+		WithFIFOCompaction(sst.FIFOCompactionOptions{MaxAge: 100 * time.Millisecond})
+	workspace, _ := newWorkSpace(configuration)
+	defer workspace.close()
+
+	for count := 1; count <= 4; count++ {
+		batch := NewBatch()
+		batch.add(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+		_ = workspace.put(batch)
+	}
+	if !eventually(func() bool { return !workspace.get(model.NewSlice([]byte("Key-1"))).Exists }) {
+		t.Fatalf("Expected key %v of an SSTable older than the max age to be missing, found %v SSTables", "Key-1", workspace.ssTables.TotalLevel0SSTables())
+	}
+}
+
+func TestRejectsPutsOnceAMemTableCanNotBeFlushed(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 32
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{})
+	workspace, _ := newWorkSpace(configuration)
+	_ = os.RemoveAll(path.Join(directory, "sst"))
+
+	var err error
+	deadline := time.Now().Add(5 * time.Second)
+	for count := 1; err == nil && time.Now().Before(deadline); count++ {
+		batch := NewBatch()
+		batch.add(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+		err = workspace.put(batch)
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err == nil {
+		t.Fatalf("Expected puts to be rejected once a memTable can not be flushed")
+	}
+	if getResult := workspace.get(model.NewSlice([]byte("Key-1"))); getResult.Value.AsString() != "Value-1" {
+		t.Fatalf("Expected the memTable which could not be flushed to stay readable, received %v", getResult.Value.AsString())
+	}
+}
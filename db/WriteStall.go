@@ -0,0 +1,197 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSlowdownImmutableMemTables = 8
+	defaultStopImmutableMemTables     = 16
+	defaultSlowdownLevel0SSTables     = 20
+	defaultStopLevel0SSTables         = 36
+	defaultSlowdownDelay              = time.Millisecond
+	defaultStopTimeout                = 10 * time.Second
+)
+
+// WriteStallReason is the threshold that delays or blocks the commits.
+type WriteStallReason string
+
+const (
+	NoWriteStall                  WriteStallReason = ""
+	SlowdownForImmutableMemTables WriteStallReason = "slowdown for immutable memTables"
+	StopForImmutableMemTables     WriteStallReason = "stop for immutable memTables"
+	SlowdownForLevel0SSTables     WriteStallReason = "slowdown for level 0 SSTables"
+	StopForLevel0SSTables         WriteStallReason = "stop for level 0 SSTables"
+)
+
+// WriteStallStatistics reports how many commits a write stall delayed or blocked, and for how long in all.
+type WriteStallStatistics struct {
+	Count    uint64
+	Duration time.Duration
+}
+
+// WriteStallOptions configure the backpressure on commits when flushes or compactions fall behind, zero values leave the defaults in place.
+// A commit is delayed by SlowdownDelay once the immutable memTables waiting to be flushed or the SSTables in level 0 reach their slowdown threshold,
+// and it is blocked while they are at their stop threshold, till a flush or a compaction brings them below it. A commit blocked for StopTimeout fails.
+// The level 0 SSTables are counted by the compaction style: leveled compaction counts every SSTable in level 0, size-tiered and time-window compaction
+// count the SSTables in the runs which are ready to be merged and FIFO compaction counts the SSTables which are due to be dropped.
+type WriteStallOptions struct {
+	SlowdownImmutableMemTables int
+	StopImmutableMemTables     int
+	SlowdownLevel0SSTables     int
+	StopLevel0SSTables         int
+	SlowdownDelay              time.Duration
+	StopTimeout                time.Duration
+}
+
+func (options WriteStallOptions) slowdownImmutableMemTablesOrDefault() int {
+	if options.SlowdownImmutableMemTables <= 0 {
+		return defaultSlowdownImmutableMemTables
+	}
+	return options.SlowdownImmutableMemTables
+}
+
+func (options WriteStallOptions) stopImmutableMemTablesOrDefault() int {
+	if options.StopImmutableMemTables <= 0 {
+		return defaultStopImmutableMemTables
+	}
+	return options.StopImmutableMemTables
+}
+
+func (options WriteStallOptions) slowdownLevel0SSTablesOrDefault() int {
+	if options.SlowdownLevel0SSTables <= 0 {
+		return defaultSlowdownLevel0SSTables
+	}
+	return options.SlowdownLevel0SSTables
+}
+
+func (options WriteStallOptions) stopLevel0SSTablesOrDefault() int {
+	if options.StopLevel0SSTables <= 0 {
+		return defaultStopLevel0SSTables
+	}
+	return options.StopLevel0SSTables
+}
+
+func (options WriteStallOptions) slowdownDelayOrDefault() time.Duration {
+	if options.SlowdownDelay <= 0 {
+		return defaultSlowdownDelay
+	}
+	return options.SlowdownDelay
+}
+
+func (options WriteStallOptions) stopTimeoutOrDefault() time.Duration {
+	if options.StopTimeout <= 0 {
+		return defaultStopTimeout
+	}
+	return options.StopTimeout
+}
+
+func (options WriteStallOptions) reasonFor(immutableMemTables, level0SSTables int) WriteStallReason {
+	switch {
+	case immutableMemTables >= options.stopImmutableMemTablesOrDefault():
+		return StopForImmutableMemTables
+	case level0SSTables >= options.stopLevel0SSTablesOrDefault():
+		return StopForLevel0SSTables
+	case immutableMemTables >= options.slowdownImmutableMemTablesOrDefault():
+		return SlowdownForImmutableMemTables
+	case level0SSTables >= options.slowdownLevel0SSTablesOrDefault():
+		return SlowdownForLevel0SSTables
+	}
+	return NoWriteStall
+}
+
+// writeStalls delays or blocks the commits as per the WriteStallOptions, a blocked commit is woken up whenever a version is installed,
+// compactions are resumed or a flush or a compaction fails.
+type writeStalls struct {
+	options    WriteStallOptions
+	current    WriteStallReason
+	statistics map[WriteStallReason]WriteStallStatistics
+	lock       sync.Mutex
+	resumable  *sync.Cond
+}
+
+func newWriteStalls(options WriteStallOptions) *writeStalls {
+	writeStalls := &writeStalls{options: options, statistics: make(map[WriteStallReason]WriteStallStatistics)}
+	writeStalls.resumable = sync.NewCond(&writeStalls.lock)
+	return writeStalls
+}
+
+// stall blocks while counts are at a stop threshold and then delays if they are at a slowdown threshold.
+func (writeStalls *writeStalls) stall(counts func() (int, int), failure func() error) error {
+	writeStalls.lock.Lock()
+	reason, err := writeStalls.blockWhileStopped(counts, failure)
+	writeStalls.current = reason
+	writeStalls.lock.Unlock()
+
+	if err != nil || reason == NoWriteStall {
+		return err
+	}
+	delay := writeStalls.options.slowdownDelayOrDefault()
+	time.Sleep(delay)
+
+	writeStalls.lock.Lock()
+	defer writeStalls.lock.Unlock()
+	writeStalls.record(reason, delay)
+	writeStalls.current = NoWriteStall
+	return nil
+}
+
+// blockWhileStopped expects the lock to be held, it fails if failure answers an error while the commit is blocked
+// or if the commit stays blocked for the stop timeout.
+func (writeStalls *writeStalls) blockWhileStopped(counts func() (int, int), failure func() error) (WriteStallReason, error) {
+	reason := writeStalls.options.reasonFor(counts())
+	if !isStop(reason) {
+		return reason, nil
+	}
+	stopReason, start, timeout := reason, time.Now(), writeStalls.options.stopTimeoutOrDefault()
+	timer := time.AfterFunc(timeout, writeStalls.resume)
+	defer timer.Stop()
+	defer func() {
+		writeStalls.record(stopReason, time.Since(start))
+	}()
+
+	for isStop(reason) {
+		writeStalls.current = reason
+		if err := failure(); err != nil {
+			return NoWriteStall, err
+		}
+		if time.Since(start) >= timeout {
+			return NoWriteStall, errors.New(fmt.Sprintf("commit was blocked for %v by the write stall: %v", timeout, stopReason))
+		}
+		writeStalls.resumable.Wait()
+		reason = writeStalls.options.reasonFor(counts())
+	}
+	return reason, nil
+}
+
+func (writeStalls *writeStalls) resume() {
+	writeStalls.lock.Lock()
+	defer writeStalls.lock.Unlock()
+
+	writeStalls.resumable.Broadcast()
+}
+
+func (writeStalls *writeStalls) record(reason WriteStallReason, duration time.Duration) {
+	statistics := writeStalls.statistics[reason]
+	statistics.Count = statistics.Count + 1
+	statistics.Duration = statistics.Duration + duration
+	writeStalls.statistics[reason] = statistics
+}
+
+func (writeStalls *writeStalls) snapshot() (WriteStallReason, map[WriteStallReason]WriteStallStatistics) {
+	writeStalls.lock.Lock()
+	defer writeStalls.lock.Unlock()
+
+	statistics := make(map[WriteStallReason]WriteStallStatistics, len(writeStalls.statistics))
+	for reason, stallStatistics := range writeStalls.statistics {
+		statistics[reason] = stallStatistics
+	}
+	return writeStalls.current, statistics
+}
+
+func isStop(reason WriteStallReason) bool {
+	return reason == StopForImmutableMemTables || reason == StopForLevel0SSTables
+}
@@ -0,0 +1,229 @@
+package db
+
+import (
+	"errors"
+	"os"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"storage-engine-workshop/storage/sst"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func noFailure() error {
+	return nil
+}
+
+func TestDecidesTheWriteStallReasonByThePendingWork(t *testing.T) {
+	options := WriteStallOptions{SlowdownImmutableMemTables: 2, StopImmutableMemTables: 4, SlowdownLevel0SSTables: 3, StopLevel0SSTables: 6}
+	for _, expectation := range []struct {
+		immutableMemTables int
+		level0SSTables     int
+		reason             WriteStallReason
+	}{
+		{1, 2, NoWriteStall},
+		{2, 2, SlowdownForImmutableMemTables},
+		{1, 3, SlowdownForLevel0SSTables},
+		{4, 3, StopForImmutableMemTables},
+		{3, 6, StopForLevel0SSTables},
+	} {
+		if reason := options.reasonFor(expectation.immutableMemTables, expectation.level0SSTables); reason != expectation.reason {
+			t.Fatalf("Expected reason %v for %v immutable memTables and %v level 0 SSTables, received %v", expectation.reason, expectation.immutableMemTables, expectation.level0SSTables, reason)
+		}
+	}
+}
+
+func TestDelaysAWriteByTheSlowdownDelay(t *testing.T) {
+	writeStalls := newWriteStalls(WriteStallOptions{SlowdownImmutableMemTables: 1, SlowdownDelay: 20 * time.Millisecond})
+
+	start := time.Now()
+	writeStalls.stall(func() (int, int) { return 1, 0 }, noFailure)
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("Expected the write to be delayed by at least %v, took %v", 20*time.Millisecond, elapsed)
+	}
+	if _, statistics := writeStalls.snapshot(); statistics[SlowdownForImmutableMemTables].Count != 1 {
+		t.Fatalf("Expected %v slowdown, received %v", 1, statistics[SlowdownForImmutableMemTables].Count)
+	}
+}
+
+func TestBlocksAWriteTillThePendingWorkIsBelowTheStopThreshold(t *testing.T) {
+	writeStalls := newWriteStalls(WriteStallOptions{StopImmutableMemTables: 2})
+	immutableMemTables := int32(2)
+	stalled := make(chan bool)
+
+	go func() {
+		writeStalls.stall(func() (int, int) { return int(atomic.LoadInt32(&immutableMemTables)), 0 }, noFailure)
+		close(stalled)
+	}()
+	time.Sleep(30 * time.Millisecond)
+	if current, _ := writeStalls.snapshot(); current != StopForImmutableMemTables {
+		t.Fatalf("Expected the current write stall to be %v, received %v", StopForImmutableMemTables, current)
+	}
+
+	atomic.StoreInt32(&immutableMemTables, 1)
+	writeStalls.resume()
+	<-stalled
+
+	current, statistics := writeStalls.snapshot()
+	if current != NoWriteStall {
+		t.Fatalf("Expected no current write stall, received %v", current)
+	}
+	if stop := statistics[StopForImmutableMemTables]; stop.Count != 1 || stop.Duration < 30*time.Millisecond {
+		t.Fatalf("Expected %v stop lasting at least %v, received %v lasting %v", 1, 30*time.Millisecond, stop.Count, stop.Duration)
+	}
+}
+
+func TestFailsAWriteBlockedForTheStopTimeout(t *testing.T) {
+	writeStalls := newWriteStalls(WriteStallOptions{StopImmutableMemTables: 2, StopTimeout: 30 * time.Millisecond})
+
+	start := time.Now()
+	if err := writeStalls.stall(func() (int, int) { return 2, 0 }, noFailure); err == nil {
+		t.Fatalf("Expected the write blocked for the stop timeout to fail")
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("Expected the write to be blocked for at least %v, took %v", 30*time.Millisecond, elapsed)
+	}
+	if current, _ := writeStalls.snapshot(); current != NoWriteStall {
+		t.Fatalf("Expected no current write stall, received %v", current)
+	}
+}
+
+func TestFailsABlockedWriteOnceAFailureIsReported(t *testing.T) {
+	writeStalls := newWriteStalls(WriteStallOptions{StopImmutableMemTables: 2})
+	failure := atomic.Value{}
+	failure.Store("")
+	failed := make(chan error)
+
+	go func() {
+		failed <- writeStalls.stall(func() (int, int) { return 2, 0 }, func() error {
+			if message := failure.Load().(string); message != "" {
+				return errors.New(message)
+			}
+			return nil
+		})
+	}()
+	time.Sleep(30 * time.Millisecond)
+	failure.Store("flush failed")
+	writeStalls.resume()
+
+	if err := <-failed; err == nil || err.Error() != "flush failed" {
+		t.Fatalf("Expected the blocked write to fail with %v, received %v", "flush failed", err)
+	}
+}
+
+func TestFailsCommitsBlockedByMergeableSSTablesUnderTheDefaultCompactionWhileCompactionsArePaused(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 32
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{}).
+		WithWriteStalls(WriteStallOptions{SlowdownLevel0SSTables: 4, StopLevel0SSTables: 4, StopTimeout: 100 * time.Millisecond})
+	db, _ := NewKeyValueDb(configuration)
+	db.PauseCompactions()
+
+	commit := func(count int) error {
+		txn := db.newTransaction()
+		_ = txn.Put(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+		return txn.Commit()
+	}
+	count := 0
+	if !eventually(func() bool {
+		count = count + 1
+		return commit(count) != nil
+	}) {
+		t.Fatalf("Expected a commit to fail once the mergeable SSTables stay at the stop threshold")
+	}
+	if statistics := db.Statistics(); statistics.WriteStalls[StopForLevel0SSTables].Count == 0 {
+		t.Fatalf("Expected commits to have been stopped for level 0 SSTables, received %v", statistics.WriteStalls)
+	}
+
+	db.ResumeCompactions()
+	if !eventually(func() bool { return commit(count+1) == nil }) {
+		t.Fatalf("Expected commits to succeed once compactions are resumed")
+	}
+}
+
+func TestFailsCommitsBlockedByLevel0SSTablesWhileCompactionsArePaused(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 32
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{}).
+		WithLeveledCompaction(sst.LeveledCompactionOptions{Level0FileTrigger: 2}).
+		WithWriteStalls(WriteStallOptions{SlowdownLevel0SSTables: 2, StopLevel0SSTables: 2, StopTimeout: 100 * time.Millisecond})
+	db, _ := NewKeyValueDb(configuration)
+	db.PauseCompactions()
+
+	commit := func(count int) error {
+		txn := db.newTransaction()
+		_ = txn.Put(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+		return txn.Commit()
+	}
+	count := 0
+	if !eventually(func() bool {
+		count = count + 1
+		return commit(count) != nil
+	}) {
+		t.Fatalf("Expected a commit to fail once level 0 SSTables stay at the stop threshold")
+	}
+	if statistics := db.Statistics(); statistics.WriteStalls[StopForLevel0SSTables].Count == 0 {
+		t.Fatalf("Expected commits to have been stopped for level 0 SSTables, received %v", statistics.WriteStalls)
+	}
+
+	db.ResumeCompactions()
+	if !eventually(func() bool { return commit(count+1) == nil }) {
+		t.Fatalf("Expected commits to succeed once compactions are resumed")
+	}
+}
+
+func TestBlocksCommitsWhileLevel0SSTablesAreAtTheStopThresholdTillTheyAreCompacted(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 10 * 1024
+	const bufferMaxSizeBytes uint64 = 32
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	configuration := NewConfiguration(directory, segmentMaxSizeBytes, bufferMaxSizeBytes, comparator.StringKeyComparator{}).
+		WithLeveledCompaction(sst.LeveledCompactionOptions{Level0FileTrigger: 2}).
+		WithWriteStalls(WriteStallOptions{SlowdownLevel0SSTables: 2, StopLevel0SSTables: 2})
+	db, _ := NewKeyValueDb(configuration)
+	db.PauseCompactions()
+
+	committed := make(chan bool)
+	go func() {
+		for count := 1; count <= 20; count++ {
+			txn := db.newTransaction()
+			_ = txn.Put(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+			_ = txn.Commit()
+		}
+		allowFlushingSSTable()
+
+		txn := db.newTransaction()
+		_ = txn.Put(model.NewSlice([]byte("Key-21")), model.NewSlice([]byte("Value-21")))
+		_ = txn.Commit()
+		close(committed)
+	}()
+	select {
+	case <-committed:
+		t.Fatalf("Expected the commits to be blocked while compactions are paused")
+	case <-time.After(1500 * time.Millisecond):
+	}
+	if statistics := db.Statistics(); statistics.CurrentWriteStall != StopForLevel0SSTables {
+		t.Fatalf("Expected the current write stall to be %v, received %v", StopForLevel0SSTables, statistics.CurrentWriteStall)
+	}
+
+	db.ResumeCompactions()
+	<-committed
+	if getResult := db.newReadonlyTransaction().Get(model.NewSlice([]byte("Key-21"))); getResult.Value.AsString() != "Value-21" {
+		t.Fatalf("Expected value to be %v, received %v", "Value-21", getResult.Value.AsString())
+	}
+	if statistics := db.Statistics(); statistics.WriteStalls[StopForLevel0SSTables].Count == 0 {
+		t.Fatalf("Expected commits to have been stopped for level 0 SSTables, received %v", statistics.WriteStalls)
+	}
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// NeverExpires marks a key/value pair without a ttl, expiresAt is stored as unix nanoseconds otherwise
+const NeverExpires int64 = 0
+
+// RemovedAt is the expiry of a tombstone written in place of a removed key, it has expired at any time after the unix epoch
+const RemovedAt int64 = 1
+
+func ExpiresAfter(ttl time.Duration) int64 {
+	return time.Now().Add(ttl).UnixNano()
+}
+
+func IsExpired(expiresAt int64, now time.Time) bool {
+	return expiresAt != NeverExpires && expiresAt <= now.UnixNano()
+}
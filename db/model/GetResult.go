@@ -1,14 +1,21 @@
 package model
 
+import "time"
+
 type GetResult struct {
 	Key, Value Slice
 	Exists     bool
+	ExpiresAt  int64
 }
 
 type MultiGetResult struct {
 	Values []GetResult
 }
 
+func (getResult GetResult) IsExpiredAt(now time.Time) bool {
+	return getResult.Exists && IsExpired(getResult.ExpiresAt, now)
+}
+
 func (multiGetResult *MultiGetResult) Add(getResult GetResult) {
 	multiGetResult.Values = append(multiGetResult.Values, getResult)
 }
@@ -1,6 +1,18 @@
 package model
 
+import "time"
+
 type KeyValuePair struct {
-	Key   Slice
-	Value Slice
+	Key       Slice
+	Value     Slice
+	ExpiresAt int64
+}
+
+func (keyValuePair KeyValuePair) IsExpiredAt(now time.Time) bool {
+	return IsExpired(keyValuePair.ExpiresAt, now)
+}
+
+// AsTombstone keeps the key and the expiry of an expired pair without its value, the tombstone keeps shadowing the older versions of the key.
+func (keyValuePair KeyValuePair) AsTombstone() KeyValuePair {
+	return KeyValuePair{Key: keyValuePair.Key, Value: NilSlice(), ExpiresAt: keyValuePair.ExpiresAt}
 }
@@ -12,6 +12,7 @@ type WAL struct {
 	directory       string
 	activeSegment   *Segment
 	passiveSegments []*Segment
+	lastSequence    uint64
 }
 
 const subDirectoryPermission = 0744
@@ -40,9 +41,10 @@ func (log *WAL) BeginTransactionHeader(totalSize uint16) error {
 		return log.openActiveSegmentAt(log.activeSegment.LastOffset(), log.activeSegment.maxSizeBytes)
 	}
 	appendToActiveSegment := func() error {
-		if err := log.activeSegment.Append(NewPersistentLogSliceTransactionHeader(totalSize)); err != nil {
+		if err := log.activeSegment.Append(NewPersistentLogSliceTransactionHeader(log.lastSequence+1, totalSize)); err != nil {
 			return err
 		}
+		log.lastSequence = log.lastSequence + 1
 		return nil
 	}
 	if log.activeSegment.IsMaxed() {
@@ -88,6 +90,27 @@ func (log *WAL) ReadAll() ([]TransactionalEntry, error) {
 	return readAllSegments()
 }
 
+func (log *WAL) ReadFrom(sequence uint64) ([]TransactionalEntry, error) {
+	return log.NewReader().ReadFrom(sequence)
+}
+
+// NewReader returns a reader of the entries appended so far, it must not run concurrently with an append.
+func (log *WAL) NewReader() *Reader {
+	segments := make([]*Segment, 0, len(log.passiveSegments)+1)
+	segments = append(segments, log.passiveSegments...)
+	segments = append(segments, log.activeSegment)
+
+	sizes := make([]int64, 0, len(segments))
+	for _, segment := range segments {
+		sizes = append(sizes, segment.store.Size())
+	}
+	return &Reader{segments: segments, sizes: sizes}
+}
+
+func (log *WAL) LastSequence() uint64 {
+	return log.lastSequence
+}
+
 func (log *WAL) Close() {
 	log.activeSegment.Close()
 	for _, segment := range log.passiveSegments {
@@ -129,7 +152,20 @@ func (log *WAL) init(segmentMaxSizeBytes uint64) error {
 		}
 		return nil
 	}
-	return reOpenSegments()
+	restoreLastSequence := func() error {
+		entries, err := log.ReadAll()
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			log.lastSequence = entries[len(entries)-1].sequence
+		}
+		return nil
+	}
+	if err := reOpenSegments(); err != nil {
+		return err
+	}
+	return restoreLastSequence()
 }
 
 func (log *WAL) openActiveSegmentAt(offset int64, segmentMaxSizeBytes uint64) error {
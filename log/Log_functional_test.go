@@ -248,3 +248,100 @@ func TestAppendsMultipleSuccessEntriesWithinMultipleTransactionsAndReadsAllOfThe
 	assertEntries(0, 0, 0, 20)
 	assertEntries(1, 20, 0, 20)
 }
+
+func TestAppendsATransactionalEntryWithExpiryAndReadsIt(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	var segmentMaxSizeBytes uint64 = 32
+	wal, _ := NewLog(directory, segmentMaxSizeBytes)
+
+	key, value := model.NewSlice([]byte("Key")), model.NewSlice([]byte("Value"))
+	persistentLogSlice := NewPersistentLogSlice(model.KeyValuePair{Key: key, Value: value, ExpiresAt: 1000})
+
+	if err := wal.BeginTransactionHeader(uint16(persistentLogSlice.Size())); err != nil {
+		log.Fatal(err)
+	}
+	if err := wal.Append(persistentLogSlice); err != nil {
+		log.Fatal(err)
+	}
+	if err := wal.MarkTransactionWith(TransactionStatusSuccess()); err != nil {
+		log.Fatal(err)
+	}
+
+	transactionalEntries, err := wal.ReadAll()
+	if err != nil {
+		log.Fatal(err)
+	}
+	onlyEntry := transactionalEntries[0]
+	if onlyEntry.keyValuePairs[0].ExpiresAt != 1000 {
+		t.Fatalf("Expected expiresAt to be %v received %v", 1000, onlyEntry.keyValuePairs[0].ExpiresAt)
+	}
+	if onlyEntry.keyValuePairs[0].Value.GetSlice().AsString() != "Value" {
+		t.Fatalf("Expected value to be %v received %v", "Value", onlyEntry.keyValuePairs[0].Value.GetSlice().AsString())
+	}
+}
+
+func TestAssignsSequencesToTransactionalEntriesAndRestoresTheLastSequenceOnReopen(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	var segmentMaxSizeBytes uint64 = 32
+	wal, _ := NewLog(directory, segmentMaxSizeBytes)
+
+	for count := 1; count <= 3; count++ {
+		persistentLogSlice := NewPersistentLogSlice(model.KeyValuePair{Key: model.NewSlice([]byte("Key-" + strconv.Itoa(count))), Value: model.NewSlice([]byte("Value"))})
+		if err := wal.BeginTransactionHeader(uint16(persistentLogSlice.Size())); err != nil {
+			log.Fatal(err)
+		}
+		if err := wal.Append(persistentLogSlice); err != nil {
+			log.Fatal(err)
+		}
+		if err := wal.MarkTransactionWith(TransactionStatusSuccess()); err != nil {
+			log.Fatal(err)
+		}
+	}
+	transactionalEntries, err := wal.ReadFrom(2)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(transactionalEntries) != 2 || transactionalEntries[0].Sequence() != 2 || transactionalEntries[1].Sequence() != 3 {
+		t.Fatalf("Expected entries with sequences %v and %v, received %v entries", 2, 3, len(transactionalEntries))
+	}
+
+	wal.Close()
+	walAfterRestart, _ := NewLog(directory, segmentMaxSizeBytes)
+	if lastSequence := walAfterRestart.LastSequence(); lastSequence != 3 {
+		t.Fatalf("Expected last sequence to be %v after restart, received %v", 3, lastSequence)
+	}
+}
+
+func TestReadsOnlyTheEntriesAppendedBeforeTheReaderWasCreated(t *testing.T) {
+	const segmentMaxSizeBytes uint64 = 64
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+	wal, _ := NewLog(directory, segmentMaxSizeBytes)
+
+	appendEntry := func(count int) {
+		persistentLogSlice := NewPersistentLogSlice(model.KeyValuePair{Key: model.NewSlice([]byte("Key-" + strconv.Itoa(count))), Value: model.NewSlice([]byte("Value"))})
+		_ = wal.BeginTransactionHeader(uint16(persistentLogSlice.Size()))
+		_ = wal.Append(persistentLogSlice)
+		_ = wal.MarkTransactionWith(TransactionStatusSuccess())
+	}
+	for count := 1; count <= 3; count++ {
+		appendEntry(count)
+	}
+	reader := wal.NewReader()
+	for count := 4; count <= 6; count++ {
+		appendEntry(count)
+	}
+
+	transactionalEntries, err := reader.ReadFrom(2)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(transactionalEntries) != 2 || transactionalEntries[0].Sequence() != 2 || transactionalEntries[1].Sequence() != 3 {
+		t.Fatalf("Expected entries with sequences %v and %v, received %v entries", 2, 3, len(transactionalEntries))
+	}
+}
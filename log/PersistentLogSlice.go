@@ -10,11 +10,14 @@ var (
 	bigEndian                           = binary.BigEndian
 	reservedEntrySize                   = unsafe.Sizeof(uint32(0))
 	reservedKeySize                     = unsafe.Sizeof(uint32(0))
-	reservedTransactionHeaderSize uint8 = 2
+	reservedExpiresAtSize               = unsafe.Sizeof(int64(0))
+	reservedSequenceSize                = unsafe.Sizeof(uint64(0))
+	reservedTransactionHeaderSize uint8 = uint8(reservedSequenceSize) + 2
 	reservedTransactionStatusSize uint8 = TransactionStatusSize()
 )
 
 type TransactionalEntry struct {
+	sequence      uint64
 	keyValuePairs []PersistentKeyValuePair
 	status        TransactionStatus
 }
@@ -31,12 +34,26 @@ func NewPersistentLogSliceKeyValuePairs(contents []byte) []PersistentKeyValuePai
 	return unmarshal(contents)
 }
 
-func NewPersistentLogSliceTransactionHeader(totalSize uint16) PersistentLogSlice {
+func NewPersistentLogSliceTransactionHeader(sequence uint64, totalSize uint16) PersistentLogSlice {
+	//The way transaction header is encoded is: 8 bytes for sequence | 2 bytes for totalSize
 	bytes := make([]byte, reservedTransactionHeaderSize)
-	bigEndian.PutUint16(bytes, totalSize)
+	bigEndian.PutUint64(bytes, sequence)
+	bigEndian.PutUint16(bytes[reservedSequenceSize:], totalSize)
 	return PersistentLogSlice{contents: bytes}
 }
 
+func (transactionalEntry TransactionalEntry) Sequence() uint64 {
+	return transactionalEntry.sequence
+}
+
+func (transactionalEntry TransactionalEntry) KeyValuePairs() []PersistentKeyValuePair {
+	return transactionalEntry.keyValuePairs
+}
+
+func (transactionalEntry TransactionalEntry) IsCommitted() bool {
+	return transactionalEntry.status.isSuccess()
+}
+
 func (persistentLogSlice PersistentLogSlice) GetPersistentContents() []byte {
 	return persistentLogSlice.contents
 }
@@ -54,7 +71,11 @@ func (persistentLogSlice *PersistentLogSlice) Add(other PersistentLogSlice) {
 }
 
 func TransactionalEntrySize(bytes []byte) uint16 {
-	return bigEndian.Uint16(bytes)
+	return bigEndian.Uint16(bytes[reservedSequenceSize:])
+}
+
+func TransactionalEntrySequence(bytes []byte) uint64 {
+	return bigEndian.Uint64(bytes)
 }
 
 func marshal(keyValuePair model.KeyValuePair) PersistentLogSlice {
@@ -62,9 +83,10 @@ func marshal(keyValuePair model.KeyValuePair) PersistentLogSlice {
 		len(keyValuePair.Key.GetRawContent()) +
 			len(keyValuePair.Value.GetRawContent()) +
 			int(reservedKeySize) +
+			int(reservedExpiresAtSize) +
 			int(reservedEntrySize)
 
-	//The way PutCommand is encoded is: 4 bytes for entrySize | 4 bytes for keySize | Key content | Value content
+	//The way PutCommand is encoded is: 4 bytes for entrySize | 4 bytes for keySize | 8 bytes for expiresAt | Key content | Value content
 	bytes := make([]byte, entrySize)
 	offset := 0
 
@@ -74,6 +96,9 @@ func marshal(keyValuePair model.KeyValuePair) PersistentLogSlice {
 	bigEndian.PutUint32(bytes[offset:], uint32(len(keyValuePair.Key.GetRawContent())))
 	offset = offset + int(reservedKeySize)
 
+	bigEndian.PutUint64(bytes[offset:], uint64(keyValuePair.ExpiresAt))
+	offset = offset + int(reservedExpiresAtSize)
+
 	copy(bytes[offset:], keyValuePair.Key.GetRawContent())
 	offset = offset + len(keyValuePair.Key.GetRawContent())
 
@@ -93,6 +118,8 @@ func unmarshal(bytes []byte) []PersistentKeyValuePair {
 		index = index + uint32(reservedEntrySize)
 		keySize := bigEndian.Uint32(bytes[index:])
 		index = index + uint32(reservedKeySize)
+		expiresAt := int64(bigEndian.Uint64(bytes[index:]))
+		index = index + uint32(reservedExpiresAtSize)
 
 		keyEndOffset := index + keySize
 		key := bytes[index:keyEndOffset]
@@ -104,8 +131,9 @@ func unmarshal(bytes []byte) []PersistentKeyValuePair {
 
 		keyValuePairs = append(keyValuePairs,
 			PersistentKeyValuePair{
-				Key:   PersistentLogSlice{contents: key},
-				Value: PersistentLogSlice{contents: value},
+				Key:       PersistentLogSlice{contents: key},
+				Value:     PersistentLogSlice{contents: value},
+				ExpiresAt: expiresAt,
 			},
 		)
 	}
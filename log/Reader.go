@@ -0,0 +1,24 @@
+package log
+
+// Reader reads the entries which were in the log when the reader was created, the log may be appended to while the reader reads.
+type Reader struct {
+	segments []*Segment
+	sizes    []int64
+}
+
+// ReadFrom reads the entries whose sequence is at least the given sequence.
+func (reader *Reader) ReadFrom(sequence uint64) ([]TransactionalEntry, error) {
+	var entries []TransactionalEntry
+	for index, segment := range reader.segments {
+		segmentEntries, err := segment.readUpTo(reader.sizes[index])
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range segmentEntries {
+			if entry.sequence >= sequence {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries, nil
+}
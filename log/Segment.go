@@ -39,6 +39,10 @@ func (segment *Segment) ReadAll() ([]TransactionalEntry, error) {
 	return segment.store.ReadAll()
 }
 
+func (segment *Segment) readUpTo(size int64) ([]TransactionalEntry, error) {
+	return segment.store.readUpTo(size)
+}
+
 func (segment *Segment) IsMaxed() bool {
 	if segment.store.Size() >= int64(segment.maxSizeBytes) {
 		return true
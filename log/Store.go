@@ -40,10 +40,15 @@ func (store *Store) Append(persistentLogSlice PersistentLogSlice) error {
 }
 
 func (store *Store) ReadAll() ([]TransactionalEntry, error) {
+	return store.readUpTo(store.size)
+}
+
+// readUpTo reads the entries in the first size bytes, it may run while the store is appended to.
+func (store *Store) readUpTo(size int64) ([]TransactionalEntry, error) {
 	var entries []TransactionalEntry
 	var currentOffset int64 = 0
 
-	for currentOffset < store.size {
+	for currentOffset < size {
 		transactionalEntries, nextOffset, err := store.readAt(currentOffset)
 		if err != nil {
 			return nil, err
@@ -66,12 +71,13 @@ func (store *Store) Close() {
 }
 
 func (store *Store) readAt(offset int64) (TransactionalEntry, int64, error) {
-	transactionEntrySizeBytes := make([]byte, reservedTransactionHeaderSize)
-	_, err := store.file.ReadAt(transactionEntrySizeBytes, offset)
+	transactionHeaderBytes := make([]byte, reservedTransactionHeaderSize)
+	_, err := store.file.ReadAt(transactionHeaderBytes, offset)
 	if err != nil {
 		return TransactionalEntry{}, -1, err
 	}
-	transactionEntrySize := TransactionalEntrySize(transactionEntrySizeBytes)
+	sequence := TransactionalEntrySequence(transactionHeaderBytes)
+	transactionEntrySize := TransactionalEntrySize(transactionHeaderBytes)
 	transactionEntryBytes := make([]byte, transactionEntrySize)
 	offset = offset + int64(reservedTransactionHeaderSize)
 
@@ -88,5 +94,5 @@ func (store *Store) readAt(offset int64) (TransactionalEntry, int64, error) {
 		return TransactionalEntry{}, -1, err
 	}
 	offset = offset + int64(reservedTransactionStatusSize)
-	return TransactionalEntry{keyValuePairs: pairs, status: TransactionStatusFrom(transactionStatusBytes)}, offset, nil
+	return TransactionalEntry{sequence: sequence, keyValuePairs: pairs, status: TransactionStatusFrom(transactionStatusBytes)}, offset, nil
 }
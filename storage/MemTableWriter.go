@@ -1,8 +1,11 @@
 package storage
 
 import (
+	"errors"
+	"log"
 	"storage-engine-workshop/storage/memory"
 	"storage-engine-workshop/storage/sst"
+	"time"
 )
 
 const (
@@ -10,45 +13,108 @@ const (
 	FAILURE
 )
 
+const (
+	maxWriteAttempts    = 5
+	initialRetryBackoff = 10 * time.Millisecond
+)
+
 type MemTableWriteStatus struct {
 	status int
 	err    error
 }
 
+func (memTableWriteStatus MemTableWriteStatus) IsSuccess() bool {
+	return memTableWriteStatus.status == SUCCESS
+}
+
+func (memTableWriteStatus MemTableWriteStatus) Err() error {
+	return memTableWriteStatus.err
+}
+
 type MemTableWriter struct {
-	memTable *memory.MemTable
-	ssTables *sst.SSTables
-	ssTable  *sst.SSTable
+	memTable     *memory.MemTable
+	ssTables     *sst.SSTables
+	ssTable      *sst.SSTable
+	scheduler    *Scheduler
+	writeSSTable func(ssTable *sst.SSTable) error
 }
 
 func NewMemTableWriter(memTable *memory.MemTable, ssTables *sst.SSTables) *MemTableWriter {
 	return &MemTableWriter{
 		memTable: memTable,
 		ssTables: ssTables,
+		writeSSTable: func(ssTable *sst.SSTable) error {
+			return ssTable.Write()
+		},
 	}
 }
 
+// NewScheduledMemTableWriter returns a MemTableWriter which writes the ssTable as a flush on the scheduler instead of a goroutine of its own.
+func NewScheduledMemTableWriter(memTable *memory.MemTable, ssTables *sst.SSTables, scheduler *Scheduler) *MemTableWriter {
+	memTableWriter := NewMemTableWriter(memTable, ssTables)
+	memTableWriter.scheduler = scheduler
+	return memTableWriter
+}
+
+// Write reserves the file id of the ssTable before it returns, so that the ssTables of memTables are ordered as the memTables were written,
+// however the writes which run in the background finish. The ssTable is created and written in the background,
+// expired and removed keys are flushed as tombstones. A failed write of the ssTable is retried with an exponential backoff,
+// keeping the file id, and the failure is reported once maxWriteAttempts writes have failed.
 func (memTableWriter *MemTableWriter) Write() <-chan MemTableWriteStatus {
-	response := make(chan MemTableWriteStatus)
+	response := make(chan MemTableWriteStatus, 1)
 
-	go func() {
-		err := memTableWriter.mutateWithSsTable()
-		if err != nil {
-			writeErrorToChannel(err, response)
+	fileId := memTableWriter.ssTables.ReserveFileId()
+	attempt, backoff := 1, initialRetryBackoff
+	var flush func()
+	flush = func() {
+		if memTableWriter.ssTable == nil {
+			if err := memTableWriter.mutateWithSsTable(fileId); err != nil {
+				memTableWriter.ssTables.ReleaseFileId(fileId)
+				writeErrorToChannel(err, response)
+				return
+			}
+		}
+		if memTableWriter.ssTable.IsEmpty() {
+			memTableWriter.ssTables.Discard(memTableWriter.ssTable)
+			writeErrorToChannel(errors.New("memTable does not contain any key value pairs to flush"), response)
 			return
 		}
-		if err := memTableWriter.ssTable.Write(); err != nil {
-			writeErrorToChannel(err, response)
+		if err := memTableWriter.writeSSTable(memTableWriter.ssTable); err != nil {
+			if attempt == maxWriteAttempts {
+				memTableWriter.ssTables.Discard(memTableWriter.ssTable)
+				writeErrorToChannel(err, response)
+				return
+			}
+			log.Default().Printf("Error while flushing the memTable, attempt %v of %v, retrying in %v, %v", attempt, maxWriteAttempts, backoff, err)
+			wait := backoff
+			attempt, backoff = attempt+1, 2*backoff
+			memTableWriter.retryAfter(wait, flush)
 			return
 		}
 		memTableWriter.ssTables.AllowSearchIn(memTableWriter.ssTable)
 		writeSuccessToChannel(response)
-	}()
+	}
+	memTableWriter.run(flush)
 	return response
 }
 
-func (memTableWriter *MemTableWriter) mutateWithSsTable() error {
-	ssTable, err := memTableWriter.ssTables.NewSSTable(memTableWriter.memTable)
+func (memTableWriter *MemTableWriter) run(flush func()) {
+	if memTableWriter.scheduler != nil {
+		memTableWriter.scheduler.ScheduleFlush(flush)
+	} else {
+		go flush()
+	}
+}
+
+// retryAfter does not hold a worker of the scheduler while it waits for the backoff.
+func (memTableWriter *MemTableWriter) retryAfter(backoff time.Duration, flush func()) {
+	time.AfterFunc(backoff, func() {
+		memTableWriter.run(flush)
+	})
+}
+
+func (memTableWriter *MemTableWriter) mutateWithSsTable(fileId int) error {
+	ssTable, err := memTableWriter.ssTables.NewReservedSSTable(memTableWriter.memTable, fileId)
 	if err != nil {
 		return err
 	}
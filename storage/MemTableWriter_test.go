@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"errors"
 	"io/ioutil"
 	"log"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"storage-engine-workshop/storage/memory"
 	"storage-engine-workshop/storage/sst"
 	"testing"
+	"time"
 )
 
 func tempDirectory() string {
@@ -27,7 +29,7 @@ func TestMemTableWriterWithSuccessAsStatus(t *testing.T) {
 
 	directory := tempDirectory()
 	defer os.RemoveAll(directory)
-	ssTables, _ := sst.NewSSTables(directory)
+	ssTables, _ := sst.NewSSTables(directory, sst.SSTableOptions{})
 
 	memTableWriter := NewMemTableWriter(memTable, ssTables)
 	statusChannel := memTableWriter.Write()
@@ -43,7 +45,7 @@ func TestMemTableWriterWithFailureAsStatus(t *testing.T) {
 
 	directory := tempDirectory()
 	defer os.RemoveAll(directory)
-	ssTables, _ := sst.NewSSTables(directory)
+	ssTables, _ := sst.NewSSTables(directory, sst.SSTableOptions{})
 
 	memTableWriter := NewMemTableWriter(emptyMemTable, ssTables)
 	statusChannel := memTableWriter.Write()
@@ -53,3 +55,117 @@ func TestMemTableWriterWithFailureAsStatus(t *testing.T) {
 		t.Fatalf("Expected memtable flush status to be FAILURE but received %v", status.status)
 	}
 }
+
+func TestMemTableWriterWritesTombstonesForTheKeysRemovedByTheCompactionFilter(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTable.Put(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk")))
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+	ssTables, _ := sst.NewSSTables(directory, sst.SSTableOptions{
+		CompactionFilter: sst.CompactionFilterFunc(func(key, value model.Slice) (sst.CompactionDecision, model.Slice) {
+			return sst.RemoveKey, model.NilSlice()
+		}),
+	})
+
+	status := <-NewMemTableWriter(memTable, ssTables).Write()
+	if status.status != SUCCESS {
+		t.Fatalf("Expected memtable flush status to be SUCCESS but received %v", status)
+	}
+	if getResult := ssTables.Get(model.NewSlice([]byte("HDD")), comparator.StringKeyComparator{}); !getResult.IsExpiredAt(time.Now()) || getResult.Value.Size() != 0 {
+		t.Fatalf("Expected a tombstone for the removed key %v, received %v", "HDD", getResult)
+	}
+}
+
+func TestMemTableWriterRetriesAFailedWriteOfTheSSTable(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTable.Put(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk")))
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+	ssTables, _ := sst.NewSSTables(directory, sst.SSTableOptions{})
+
+	attempts := 0
+	memTableWriter := NewScheduledMemTableWriter(memTable, ssTables, NewScheduler(1))
+	memTableWriter.writeSSTable = func(ssTable *sst.SSTable) error {
+		attempts = attempts + 1
+		if attempts < 3 {
+			return errors.New("disk is full")
+		}
+		return ssTable.Write()
+	}
+	status := <-memTableWriter.Write()
+
+	if status.status != SUCCESS || attempts != 3 {
+		t.Fatalf("Expected memtable flush status to be SUCCESS after %v attempts but received %v after %v attempts", 3, status, attempts)
+	}
+	if getResult := ssTables.Get(model.NewSlice([]byte("HDD")), comparator.StringKeyComparator{}); getResult.Value.AsString() != "Hard disk" {
+		t.Fatalf("Expected value to be %v, received %v", "Hard disk", getResult.Value.AsString())
+	}
+}
+
+func TestMemTableWriterWithFailureAsStatusOnceEveryAttemptFails(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTable.Put(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk")))
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+	ssTables, _ := sst.NewSSTables(directory, sst.SSTableOptions{})
+
+	attempts := 0
+	memTableWriter := NewMemTableWriter(memTable, ssTables)
+	memTableWriter.writeSSTable = func(ssTable *sst.SSTable) error {
+		attempts = attempts + 1
+		return errors.New("disk is full")
+	}
+	status := <-memTableWriter.Write()
+
+	if status.status != FAILURE || attempts != maxWriteAttempts {
+		t.Fatalf("Expected memtable flush status to be FAILURE after %v attempts but received %v after %v attempts", maxWriteAttempts, status, attempts)
+	}
+}
+
+func TestMemTableWriterCreatesTheSSTableOnTheSchedulerWithoutBlockingTheCaller(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTable.Put(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk")))
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+	filtering, releaseFilter := make(chan bool, 1), make(chan bool)
+	ssTables, _ := sst.NewSSTables(directory, sst.SSTableOptions{
+		CompactionFilter: sst.CompactionFilterFunc(func(key, value model.Slice) (sst.CompactionDecision, model.Slice) {
+			filtering <- true
+			<-releaseFilter
+			return sst.KeepValue, value
+		}),
+	})
+	scheduler := NewScheduler(1)
+	defer scheduler.Stop()
+
+	returned := make(chan (<-chan MemTableWriteStatus))
+	go func() {
+		returned <- NewScheduledMemTableWriter(memTable, ssTables, scheduler).Write()
+	}()
+	var statusChannel <-chan MemTableWriteStatus
+	select {
+	case statusChannel = <-returned:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected Write to return while the compaction filter is running")
+	}
+	<-filtering
+	snapshotTaken := make(chan bool)
+	go func() {
+		ssTables.Snapshot().Release()
+		close(snapshotTaken)
+	}()
+	select {
+	case <-snapshotTaken:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected a snapshot to be taken while the compaction filter is running")
+	}
+
+	close(releaseFilter)
+	if status := <-statusChannel; status.status != SUCCESS {
+		t.Fatalf("Expected memtable flush status to be SUCCESS but received %v", status)
+	}
+}
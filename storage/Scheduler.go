@@ -0,0 +1,127 @@
+package storage
+
+import "sync"
+
+const defaultBackgroundWorkers = 2
+
+// Scheduler runs flushes and compactions on a fixed number of background workers. A worker always picks a queued flush
+// before a queued compaction, so that compactions do not hold back the memTables waiting to be flushed.
+// Pausing compactions keeps the queued compactions from starting, the compactions which are running finish.
+// Stopping the scheduler drops the queued compactions, runs the queued flushes and then stops the workers.
+type Scheduler struct {
+	flushes           []func()
+	compactions       []func()
+	compactionsPaused bool
+	lock              sync.Mutex
+	jobAvailable      *sync.Cond
+	done              chan struct{}
+	workers           sync.WaitGroup
+}
+
+// NewScheduler starts the workers, 2 workers if workers is not positive.
+func NewScheduler(workers int) *Scheduler {
+	if workers <= 0 {
+		workers = defaultBackgroundWorkers
+	}
+	scheduler := &Scheduler{done: make(chan struct{})}
+	scheduler.jobAvailable = sync.NewCond(&scheduler.lock)
+	scheduler.workers.Add(workers)
+	for worker := 0; worker < workers; worker++ {
+		go scheduler.work()
+	}
+	return scheduler
+}
+
+// Stop blocks till the queued flushes and the running jobs finish and every worker has exited, stopping a stopped scheduler does nothing.
+// The jobs scheduled after Stop are dropped.
+func (scheduler *Scheduler) Stop() {
+	scheduler.lock.Lock()
+	if !scheduler.isStopped() {
+		close(scheduler.done)
+		scheduler.compactions = nil
+		scheduler.jobAvailable.Broadcast()
+	}
+	scheduler.lock.Unlock()
+
+	scheduler.workers.Wait()
+}
+
+func (scheduler *Scheduler) ScheduleFlush(flush func()) {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+
+	if scheduler.isStopped() {
+		return
+	}
+	scheduler.flushes = append(scheduler.flushes, flush)
+	scheduler.jobAvailable.Signal()
+}
+
+func (scheduler *Scheduler) ScheduleCompaction(compaction func()) {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+
+	if scheduler.isStopped() {
+		return
+	}
+	scheduler.compactions = append(scheduler.compactions, compaction)
+	scheduler.jobAvailable.Signal()
+}
+
+func (scheduler *Scheduler) PauseCompactions() {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+
+	scheduler.compactionsPaused = true
+}
+
+func (scheduler *Scheduler) ResumeCompactions() {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+
+	scheduler.compactionsPaused = false
+	scheduler.jobAvailable.Broadcast()
+}
+
+func (scheduler *Scheduler) work() {
+	defer scheduler.workers.Done()
+	for {
+		job := scheduler.nextJob()
+		if job == nil {
+			return
+		}
+		job()
+	}
+}
+
+// nextJob answers nil once the scheduler is stopped and there is no queued flush.
+func (scheduler *Scheduler) nextJob() func() {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+
+	for {
+		if len(scheduler.flushes) > 0 {
+			job := scheduler.flushes[0]
+			scheduler.flushes = scheduler.flushes[1:]
+			return job
+		}
+		if scheduler.isStopped() {
+			return nil
+		}
+		if len(scheduler.compactions) > 0 && !scheduler.compactionsPaused {
+			job := scheduler.compactions[0]
+			scheduler.compactions = scheduler.compactions[1:]
+			return job
+		}
+		scheduler.jobAvailable.Wait()
+	}
+}
+
+func (scheduler *Scheduler) isStopped() bool {
+	select {
+	case <-scheduler.done:
+		return true
+	default:
+		return false
+	}
+}
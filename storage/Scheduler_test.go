@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRunsAQueuedFlushBeforeAQueuedCompaction(t *testing.T) {
+	scheduler := NewScheduler(1)
+	blocked, finished := make(chan bool), make(chan string, 2)
+	scheduler.ScheduleFlush(func() { <-blocked })
+
+	scheduler.ScheduleCompaction(func() { finished <- "compaction" })
+	scheduler.ScheduleFlush(func() { finished <- "flush" })
+	close(blocked)
+
+	if job := <-finished; job != "flush" {
+		t.Fatalf("Expected %v to run first, received %v", "flush", job)
+	}
+	if job := <-finished; job != "compaction" {
+		t.Fatalf("Expected %v to run second, received %v", "compaction", job)
+	}
+}
+
+func TestRunsCompactionsOnlyOnceResumed(t *testing.T) {
+	scheduler := NewScheduler(2)
+	scheduler.PauseCompactions()
+	finished := make(chan string, 2)
+
+	scheduler.ScheduleCompaction(func() { finished <- "compaction" })
+	scheduler.ScheduleFlush(func() { finished <- "flush" })
+
+	if job := <-finished; job != "flush" {
+		t.Fatalf("Expected %v to run while compactions are paused, received %v", "flush", job)
+	}
+	select {
+	case job := <-finished:
+		t.Fatalf("Expected no job to run while compactions are paused, received %v", job)
+	case <-time.After(50 * time.Millisecond):
+	}
+	scheduler.ResumeCompactions()
+	if job := <-finished; job != "compaction" {
+		t.Fatalf("Expected %v to run once resumed, received %v", "compaction", job)
+	}
+}
+
+func TestStopsTheWorkersOnceTheRunningJobsFinish(t *testing.T) {
+	scheduler := NewScheduler(2)
+	blocked, finished := make(chan bool), make(chan string, 2)
+	scheduler.ScheduleFlush(func() {
+		<-blocked
+		finished <- "flush"
+	})
+	time.Sleep(10 * time.Millisecond)
+
+	stopped := make(chan bool)
+	go func() {
+		scheduler.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatalf("Expected Stop to wait for the running flush to finish")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(blocked)
+	<-stopped
+
+	scheduler.ScheduleCompaction(func() { finished <- "compaction" })
+	if job := <-finished; job != "flush" {
+		t.Fatalf("Expected %v to finish before the scheduler stopped, received %v", "flush", job)
+	}
+	select {
+	case job := <-finished:
+		t.Fatalf("Expected no job to run once the scheduler is stopped, received %v", job)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LRUCache holds values within a capacity expressed as the total charge of its values, usually their size in bytes.
+// Putting a value evicts the least recently used values till the total charge fits the capacity,
+// a value whose charge alone exceeds the capacity is not cached.
+type LRUCache struct {
+	capacity int64
+	usage    int64
+	entries  map[string]*list.Element
+	recency  *list.List
+	lock     sync.Mutex
+}
+
+type lruEntry struct {
+	key    string
+	value  interface{}
+	charge int64
+}
+
+func NewLRUCache(capacity int64) *LRUCache {
+	return &LRUCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		recency:  list.New(),
+	}
+}
+
+func (lruCache *LRUCache) Get(key string) (interface{}, bool) {
+	lruCache.lock.Lock()
+	defer lruCache.lock.Unlock()
+
+	if element, ok := lruCache.entries[key]; ok {
+		lruCache.recency.MoveToFront(element)
+		return element.Value.(*lruEntry).value, true
+	}
+	return nil, false
+}
+
+func (lruCache *LRUCache) Put(key string, value interface{}, charge int64) {
+	lruCache.lock.Lock()
+	defer lruCache.lock.Unlock()
+
+	lruCache.remove(key)
+	if charge > lruCache.capacity {
+		return
+	}
+	lruCache.entries[key] = lruCache.recency.PushFront(&lruEntry{key: key, value: value, charge: charge})
+	lruCache.usage = lruCache.usage + charge
+
+	for lruCache.usage > lruCache.capacity {
+		lruCache.remove(lruCache.recency.Back().Value.(*lruEntry).key)
+	}
+}
+
+func (lruCache *LRUCache) Remove(key string) {
+	lruCache.lock.Lock()
+	defer lruCache.lock.Unlock()
+
+	lruCache.remove(key)
+}
+
+func (lruCache *LRUCache) Usage() int64 {
+	lruCache.lock.Lock()
+	defer lruCache.lock.Unlock()
+
+	return lruCache.usage
+}
+
+func (lruCache *LRUCache) remove(key string) {
+	if element, ok := lruCache.entries[key]; ok {
+		lruCache.recency.Remove(element)
+		delete(lruCache.entries, key)
+		lruCache.usage = lruCache.usage - element.Value.(*lruEntry).charge
+	}
+}
@@ -0,0 +1,58 @@
+package cache
+
+import "testing"
+
+func TestGetsAValuePutInTheCache(t *testing.T) {
+	lruCache := NewLRUCache(100)
+	lruCache.Put("HDD", "Hard disk", 10)
+
+	value, ok := lruCache.Get("HDD")
+	if !ok || value.(string) != "Hard disk" {
+		t.Fatalf("Expected value to be %v, received %v", "Hard disk", value)
+	}
+	if lruCache.Usage() != 10 {
+		t.Fatalf("Expected usage to be %v, received %v", 10, lruCache.Usage())
+	}
+}
+
+func TestEvictsTheLeastRecentlyUsedValueOnceTheCapacityIsExceeded(t *testing.T) {
+	lruCache := NewLRUCache(20)
+	lruCache.Put("HDD", "Hard disk", 10)
+	lruCache.Put("SDD", "Solid state", 10)
+	lruCache.Get("HDD")
+	lruCache.Put("PMEM", "Persistent memory", 10)
+
+	if _, ok := lruCache.Get("SDD"); ok {
+		t.Fatalf("Expected %v to be evicted but was present", "SDD")
+	}
+	if _, ok := lruCache.Get("HDD"); !ok {
+		t.Fatalf("Expected %v to be present but was evicted", "HDD")
+	}
+	if lruCache.Usage() != 20 {
+		t.Fatalf("Expected usage to be %v, received %v", 20, lruCache.Usage())
+	}
+}
+
+func TestDoesNotCacheAValueLargerThanTheCapacity(t *testing.T) {
+	lruCache := NewLRUCache(20)
+	lruCache.Put("HDD", "Hard disk", 10)
+	lruCache.Put("Tape", "Magnetic tape", 30)
+
+	if _, ok := lruCache.Get("Tape"); ok {
+		t.Fatalf("Expected %v to not be cached", "Tape")
+	}
+	if _, ok := lruCache.Get("HDD"); !ok {
+		t.Fatalf("Expected %v to remain cached", "HDD")
+	}
+}
+
+func TestReplacesAValueWithTheSameKey(t *testing.T) {
+	lruCache := NewLRUCache(100)
+	lruCache.Put("HDD", "Hard disk", 10)
+	lruCache.Put("HDD", "Hard disk drive", 15)
+
+	value, _ := lruCache.Get("HDD")
+	if value.(string) != "Hard disk drive" || lruCache.Usage() != 15 {
+		t.Fatalf("Expected value %v with usage %v, received %v with usage %v", "Hard disk drive", 15, value, lruCache.Usage())
+	}
+}
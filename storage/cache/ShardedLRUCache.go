@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"hash/fnv"
+	"sync/atomic"
+)
+
+type Statistics struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// ShardedLRUCache splits its capacity evenly across LRUCache shards, a key always maps to the same shard
+// which lets concurrent readers of different keys avoid contending on a single lock.
+type ShardedLRUCache struct {
+	shards []*LRUCache
+	hits   uint64
+	misses uint64
+}
+
+func NewShardedLRUCache(capacity int64, totalShards int) *ShardedLRUCache {
+	if totalShards <= 0 {
+		totalShards = 1
+	}
+	shards := make([]*LRUCache, totalShards)
+	for index := range shards {
+		shards[index] = NewLRUCache(capacity / int64(totalShards))
+	}
+	return &ShardedLRUCache{shards: shards}
+}
+
+func (shardedLRUCache *ShardedLRUCache) Get(key string) (interface{}, bool) {
+	value, ok := shardedLRUCache.shardFor(key).Get(key)
+	if ok {
+		atomic.AddUint64(&shardedLRUCache.hits, 1)
+	} else {
+		atomic.AddUint64(&shardedLRUCache.misses, 1)
+	}
+	return value, ok
+}
+
+func (shardedLRUCache *ShardedLRUCache) Put(key string, value interface{}, charge int64) {
+	shardedLRUCache.shardFor(key).Put(key, value, charge)
+}
+
+func (shardedLRUCache *ShardedLRUCache) Remove(key string) {
+	shardedLRUCache.shardFor(key).Remove(key)
+}
+
+func (shardedLRUCache *ShardedLRUCache) Usage() int64 {
+	var usage int64
+	for _, shard := range shardedLRUCache.shards {
+		usage = usage + shard.Usage()
+	}
+	return usage
+}
+
+func (shardedLRUCache *ShardedLRUCache) Statistics() Statistics {
+	return Statistics{
+		Hits:   atomic.LoadUint64(&shardedLRUCache.hits),
+		Misses: atomic.LoadUint64(&shardedLRUCache.misses),
+	}
+}
+
+func (shardedLRUCache *ShardedLRUCache) shardFor(key string) *LRUCache {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key))
+	return shardedLRUCache.shards[hash.Sum32()%uint32(len(shardedLRUCache.shards))]
+}
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestGetsValuesPutAcrossShards(t *testing.T) {
+	shardedLRUCache := NewShardedLRUCache(1600, 16)
+	for count := 0; count < 50; count++ {
+		shardedLRUCache.Put("Key-"+strconv.Itoa(count), count, 1)
+	}
+	for count := 0; count < 50; count++ {
+		if value, ok := shardedLRUCache.Get("Key-" + strconv.Itoa(count)); !ok || value.(int) != count {
+			t.Fatalf("Expected value to be %v, received %v", count, value)
+		}
+	}
+	if shardedLRUCache.Usage() != 50 {
+		t.Fatalf("Expected usage to be %v, received %v", 50, shardedLRUCache.Usage())
+	}
+}
+
+func TestCountsHitsAndMisses(t *testing.T) {
+	shardedLRUCache := NewShardedLRUCache(1600, 16)
+	shardedLRUCache.Put("HDD", "Hard disk", 10)
+
+	shardedLRUCache.Get("HDD")
+	shardedLRUCache.Get("HDD")
+	shardedLRUCache.Get("SDD")
+
+	statistics := shardedLRUCache.Statistics()
+	if statistics.Hits != 2 || statistics.Misses != 1 {
+		t.Fatalf("Expected %v hits and %v misses, received %v hits and %v misses", 2, 1, statistics.Hits, statistics.Misses)
+	}
+}
@@ -8,6 +8,10 @@ import (
 type ByteWiseComparator struct {
 }
 
+func (comparator ByteWiseComparator) Name() string {
+	return "ByteWiseComparator"
+}
+
 func (comparator ByteWiseComparator) Compare(one model.Slice, other model.Slice) int {
 	return bytes.Compare(one.GetRawContent(), other.GetRawContent())
 }
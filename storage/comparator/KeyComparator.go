@@ -6,4 +6,5 @@ import (
 
 type KeyComparator interface {
 	Compare(one model.Slice, other model.Slice) int
+	Name() string
 }
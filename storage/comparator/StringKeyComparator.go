@@ -8,6 +8,10 @@ import (
 type StringKeyComparator struct {
 }
 
+func (comparator StringKeyComparator) Name() string {
+	return "StringKeyComparator"
+}
+
 func (comparator StringKeyComparator) Compare(one model.Slice, other model.Slice) int {
 	return strings.Compare(string(one.GetRawContent()), string(other.GetRawContent()))
 }
@@ -0,0 +1,51 @@
+package compression
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Codec compresses SSTable blocks, the id of the codec is stored with every block it compresses
+// so a block is decompressed by the same codec whatever the configuration was when the block was written.
+type Codec interface {
+	Id() byte
+	Name() string
+	Compress(contents []byte) ([]byte, error)
+	Decompress(contents []byte) ([]byte, error)
+}
+
+var (
+	codecLock  sync.RWMutex
+	codecsById = map[byte]Codec{}
+	NoCodec    = noCodec{}
+	FlateCodec = flateCodec{}
+)
+
+func init() {
+	codecsById[NoCodec.Id()] = NoCodec
+	codecsById[FlateCodec.Id()] = FlateCodec
+}
+
+// Register makes the codec available for reading the blocks it compressed, an id can be registered only once.
+func Register(codec Codec) error {
+	codecLock.Lock()
+	defer codecLock.Unlock()
+
+	if existing, ok := codecsById[codec.Id()]; ok {
+		return errors.New(fmt.Sprintf("codec id %v is already registered by %v", codec.Id(), existing.Name()))
+	}
+	codecsById[codec.Id()] = codec
+	return nil
+}
+
+func CodecFor(id byte) (Codec, error) {
+	codecLock.RLock()
+	defer codecLock.RUnlock()
+
+	codec, ok := codecsById[id]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("no codec is registered with id %v", id))
+	}
+	return codec, nil
+}
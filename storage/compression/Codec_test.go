@@ -0,0 +1,70 @@
+package compression
+
+import (
+	"bytes"
+	"testing"
+)
+
+type reversingCodec struct{}
+
+func (codec reversingCodec) Id() byte {
+	return 200
+}
+
+func (codec reversingCodec) Name() string {
+	return "reversing"
+}
+
+func (codec reversingCodec) Compress(contents []byte) ([]byte, error) {
+	reversed := make([]byte, len(contents))
+	for index, content := range contents {
+		reversed[len(contents)-1-index] = content
+	}
+	return reversed, nil
+}
+
+func (codec reversingCodec) Decompress(contents []byte) ([]byte, error) {
+	return codec.Compress(contents)
+}
+
+func TestCompressesAndDecompressesWithFlate(t *testing.T) {
+	contents := bytes.Repeat([]byte(`{"name": "storage engine", "kind": "lsm"}`), 20)
+	compressed, err := FlateCodec.Compress(contents)
+	if err != nil {
+		t.Fatalf("Expected no error while compressing, received %v", err)
+	}
+	if len(compressed) >= len(contents) {
+		t.Fatalf("Expected the compressed contents to be smaller than %v bytes, received %v bytes", len(contents), len(compressed))
+	}
+	decompressed, err := FlateCodec.Decompress(compressed)
+	if err != nil {
+		t.Fatalf("Expected no error while decompressing, received %v", err)
+	}
+	if !bytes.Equal(decompressed, contents) {
+		t.Fatalf("Expected %v, received %v", string(contents), string(decompressed))
+	}
+}
+
+func TestFindsTheBuiltInCodecsById(t *testing.T) {
+	for _, expected := range []Codec{NoCodec, FlateCodec} {
+		codec, err := CodecFor(expected.Id())
+		if err != nil || codec.Name() != expected.Name() {
+			t.Fatalf("Expected codec %v, received %v with error %v", expected.Name(), codec, err)
+		}
+	}
+}
+
+func TestRegistersACodecOnlyOnce(t *testing.T) {
+	if _, err := CodecFor(reversingCodec{}.Id()); err == nil {
+		t.Fatalf("Expected an error for an unregistered codec id")
+	}
+	if err := Register(reversingCodec{}); err != nil {
+		t.Fatalf("Expected no error while registering a codec, received %v", err)
+	}
+	if err := Register(reversingCodec{}); err == nil {
+		t.Fatalf("Expected an error while registering a codec id twice")
+	}
+	if codec, err := CodecFor(reversingCodec{}.Id()); err != nil || codec.Name() != "reversing" {
+		t.Fatalf("Expected codec %v, received %v with error %v", "reversing", codec, err)
+	}
+}
@@ -0,0 +1,38 @@
+package compression
+
+import (
+	"bytes"
+	"compress/flate"
+	"io/ioutil"
+)
+
+type flateCodec struct{}
+
+func (codec flateCodec) Id() byte {
+	return 1
+}
+
+func (codec flateCodec) Name() string {
+	return "flate"
+}
+
+func (codec flateCodec) Compress(contents []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer, err := flate.NewWriter(&buffer, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(contents); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (codec flateCodec) Decompress(contents []byte) ([]byte, error) {
+	reader := flate.NewReader(bytes.NewReader(contents))
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
@@ -0,0 +1,19 @@
+package compression
+
+type noCodec struct{}
+
+func (codec noCodec) Id() byte {
+	return 0
+}
+
+func (codec noCodec) Name() string {
+	return "none"
+}
+
+func (codec noCodec) Compress(contents []byte) ([]byte, error) {
+	return contents, nil
+}
+
+func (codec noCodec) Decompress(contents []byte) ([]byte, error) {
+	return contents, nil
+}
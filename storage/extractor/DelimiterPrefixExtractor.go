@@ -0,0 +1,24 @@
+package extractor
+
+import (
+	"bytes"
+	"storage-engine-workshop/db/model"
+)
+
+// DelimiterPrefixExtractor extracts the key contents up to and including the first delimiter,
+// "tenant:" is the prefix of "tenant:entity:id" with ':' as the delimiter.
+type DelimiterPrefixExtractor struct {
+	delimiter byte
+}
+
+func NewDelimiterPrefixExtractor(delimiter byte) DelimiterPrefixExtractor {
+	return DelimiterPrefixExtractor{delimiter: delimiter}
+}
+
+func (prefixExtractor DelimiterPrefixExtractor) Extract(key model.Slice) (model.Slice, bool) {
+	index := bytes.IndexByte(key.GetRawContent(), prefixExtractor.delimiter)
+	if index == -1 {
+		return model.NilSlice(), false
+	}
+	return model.NewSlice(key.GetRawContent()[:index+1]), true
+}
@@ -0,0 +1,18 @@
+package extractor
+
+import "storage-engine-workshop/db/model"
+
+type FixedLengthPrefixExtractor struct {
+	length int
+}
+
+func NewFixedLengthPrefixExtractor(length int) FixedLengthPrefixExtractor {
+	return FixedLengthPrefixExtractor{length: length}
+}
+
+func (prefixExtractor FixedLengthPrefixExtractor) Extract(key model.Slice) (model.Slice, bool) {
+	if prefixExtractor.length <= 0 || key.Size() < prefixExtractor.length {
+		return model.NilSlice(), false
+	}
+	return model.NewSlice(key.GetRawContent()[:prefixExtractor.length]), true
+}
@@ -0,0 +1,10 @@
+package extractor
+
+import "storage-engine-workshop/db/model"
+
+// PrefixExtractor returns the prefix of a key and false if the key does not have one.
+// An implementation must return the same prefix for every key that starts with an extracted prefix,
+// that is what allows a prefix scan to consult the prefix in a bloom filter.
+type PrefixExtractor interface {
+	Extract(key model.Slice) (model.Slice, bool)
+}
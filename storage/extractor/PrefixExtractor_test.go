@@ -0,0 +1,32 @@
+package extractor
+
+import (
+	"storage-engine-workshop/db/model"
+	"testing"
+)
+
+func TestExtractsAFixedLengthPrefix(t *testing.T) {
+	prefix, ok := NewFixedLengthPrefixExtractor(3).Extract(model.NewSlice([]byte("tenant")))
+	if !ok || prefix.AsString() != "ten" {
+		t.Fatalf("Expected prefix to be %v, received %v", "ten", prefix.AsString())
+	}
+}
+
+func TestDoesNotExtractAFixedLengthPrefixFromAShorterKey(t *testing.T) {
+	if _, ok := NewFixedLengthPrefixExtractor(3).Extract(model.NewSlice([]byte("te"))); ok {
+		t.Fatalf("Expected no prefix to be extracted from a key shorter than the prefix length")
+	}
+}
+
+func TestExtractsAPrefixUptoTheFirstDelimiter(t *testing.T) {
+	prefix, ok := NewDelimiterPrefixExtractor(':').Extract(model.NewSlice([]byte("tenant:entity:id")))
+	if !ok || prefix.AsString() != "tenant:" {
+		t.Fatalf("Expected prefix to be %v, received %v", "tenant:", prefix.AsString())
+	}
+}
+
+func TestDoesNotExtractAPrefixFromAKeyWithoutDelimiter(t *testing.T) {
+	if _, ok := NewDelimiterPrefixExtractor(':').Extract(model.NewSlice([]byte("tenant"))); ok {
+		t.Fatalf("Expected no prefix to be extracted from a key without the delimiter")
+	}
+}
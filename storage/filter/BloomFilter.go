@@ -6,6 +6,7 @@ import (
 	"github.com/spaolacci/murmur3"
 	"math"
 	"storage-engine-workshop/db/model"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -13,15 +14,20 @@ var aByte byte
 
 const byteSize = int(unsafe.Sizeof(&aByte))
 
+// BloomFilter is created with a reference held by BloomFilters, every other holder acquires its own reference.
+// The memory mapped file is unmapped once the last reference is released.
+// The file starts with a Header which records the parameters of the bloom filter, the bit vector follows the header.
 type BloomFilter struct {
 	capacity              int
 	bitVectorSize         int
 	bitsPerHashFunction   int
 	numberOfHashFunctions int
+	seed                  uint32
 	dataSize              int
 	fileName              string
 	falsePositiveRate     float64
 	store                 *Store
+	references            int32
 }
 
 func newBloomFilter(capacity int, dataSize int, falsePositiveRate float64, fileName string) (*BloomFilter, error) {
@@ -30,11 +36,11 @@ func newBloomFilter(capacity int, dataSize int, falsePositiveRate float64, fileN
 	bitVectorSize = bitVectorSize / byteSize
 	bitVectorSize = bitVectorSize + byteSize
 
-	store, err := NewStore(fileName, dataSize+bitVectorSize)
+	store, err := NewStore(fileName, headerSize+dataSize+bitVectorSize)
 	if err != nil {
 		return nil, err
 	}
-	return &BloomFilter{
+	bloomFilter := &BloomFilter{
 		capacity:              capacity,
 		bitVectorSize:         bitVectorSize,
 		bitsPerHashFunction:   bitsPerHashFunction,
@@ -43,14 +49,53 @@ func newBloomFilter(capacity int, dataSize int, falsePositiveRate float64, fileN
 		fileName:              fileName,
 		falsePositiveRate:     falsePositiveRate,
 		store:                 store,
+		references:            1,
+	}
+	bloomFilter.header().Write(store)
+	return bloomFilter, nil
+}
+
+// openBloomFilter reloads the bloom filter only from the header of its file, without resizing the file.
+func openBloomFilter(fileName string) (*BloomFilter, error) {
+	store, err := OpenStore(fileName)
+	if err != nil {
+		return nil, err
+	}
+	header, err := ReadHeader(store)
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	return &BloomFilter{
+		capacity:              int(header.Capacity),
+		bitVectorSize:         int(header.BitVectorSize),
+		bitsPerHashFunction:   int(header.BitsPerHashFunction),
+		numberOfHashFunctions: int(header.NumberOfHashFunctions),
+		seed:                  header.Seed,
+		dataSize:              store.Size() - headerSize,
+		fileName:              fileName,
+		store:                 store,
+		references:            1,
 	}, nil
 }
 
+func (bloomFilter *BloomFilter) header() Header {
+	return Header{
+		FormatVersion:         currentFormatVersion,
+		Capacity:              uint64(bloomFilter.capacity),
+		BitVectorSize:         uint64(bloomFilter.bitVectorSize),
+		NumberOfHashFunctions: uint32(bloomFilter.numberOfHashFunctions),
+		BitsPerHashFunction:   uint64(bloomFilter.bitsPerHashFunction),
+		Seed:                  bloomFilter.seed,
+	}
+}
+
 func (bloomFilter *BloomFilter) Put(key model.Slice) error {
 	indices := bloomFilter.keyIndices(key)
 
 	for index := 0; index < len(indices); index++ {
 		bytePosition, mask := bloomFilter.bitPositionInByte(indices[index])
+		bytePosition = bytePosition + headerSize
 		if int(bytePosition) >= bloomFilter.store.Size() {
 			return errors.New(fmt.Sprintf("bytePosition %v is greater than bloom filter file size for indices[index] %v", bytePosition, indices[index]))
 		}
@@ -64,6 +109,7 @@ func (bloomFilter *BloomFilter) Has(key model.Slice) bool {
 
 	for index := 0; index < len(indices); index++ {
 		bytePosition, mask := bloomFilter.bitPositionInByte(indices[index])
+		bytePosition = bytePosition + headerSize
 		if int(bytePosition) >= bloomFilter.store.Size() {
 			return false
 		}
@@ -74,10 +120,25 @@ func (bloomFilter *BloomFilter) Has(key model.Slice) bool {
 	return true
 }
 
+func (bloomFilter *BloomFilter) FileName() string {
+	return bloomFilter.fileName
+}
+
 func (bloomFilter *BloomFilter) Close() {
 	bloomFilter.store.Close()
 }
 
+func (bloomFilter *BloomFilter) Acquire() {
+	atomic.AddInt32(&bloomFilter.references, 1)
+}
+
+// Release closes the bloom filter once the last reference is released, the bloom filter must not be used by the releasing holder afterwards.
+func (bloomFilter *BloomFilter) Release() {
+	if atomic.AddInt32(&bloomFilter.references, -1) == 0 {
+		bloomFilter.Close()
+	}
+}
+
 func (bloomFilter *BloomFilter) bitPositionInByte(keyIndex uint64) (uint64, byte) {
 	quotient, remainder := int64(keyIndex)/int64(byteSize), int64(keyIndex)%int64(byteSize)
 	valueWithMostSignificantBit := int64(math.Pow(2, float64(byteSize)-1)) //128
@@ -101,7 +162,7 @@ func (bloomFilter *BloomFilter) keyIndices(key model.Slice) []uint64 {
 		return uint64(index*bloomFilter.bitsPerHashFunction) + (hash % uint64(bloomFilter.bitsPerHashFunction))
 	}
 	for index := 0; index < bloomFilter.numberOfHashFunctions; index++ {
-		hash := runHash(key.GetRawContent(), uint32(index))
+		hash := runHash(key.GetRawContent(), bloomFilter.seed+uint32(index))
 		indices = append(indices, indexForHash(hash, index))
 	}
 	return indices
@@ -3,19 +3,18 @@ package filter
 import (
 	"errors"
 	"fmt"
-	"io/fs"
 	"io/ioutil"
 	"os"
 	"path"
 	"storage-engine-workshop/db/model"
-	"strconv"
-	"strings"
+	"sync"
 )
 
 type BloomFilters struct {
 	directory         string
 	falsePositiveRate float64
 	filters           []*BloomFilter
+	lock              sync.Mutex
 }
 
 type BloomFilterOptions struct {
@@ -56,18 +55,46 @@ func (bloomFilters *BloomFilters) NewBloomFilter(options BloomFilterOptions) (*B
 	if filter, err := newBloomFilter(minCapacityToEnsureZeroFalseNegatives(options), options.DataSize, bloomFilters.falsePositiveRate, fileName); err != nil {
 		return nil, err
 	} else {
+		bloomFilters.lock.Lock()
+		defer bloomFilters.lock.Unlock()
+
 		bloomFilters.filters = append(bloomFilters.filters, filter)
 		return filter, nil
 	}
 }
 
+// Remove deletes the file of the bloom filter and releases the reference BloomFilters holds on it,
+// the bloom filter stays usable, unlinked, till the other holders release their references.
+func (bloomFilters *BloomFilters) Remove(bloomFilter *BloomFilter) error {
+	bloomFilters.lock.Lock()
+	defer bloomFilters.lock.Unlock()
+
+	for index, filter := range bloomFilters.filters {
+		if filter == bloomFilter {
+			bloomFilters.filters = append(bloomFilters.filters[:index], bloomFilters.filters[index+1:]...)
+			break
+		}
+	}
+	defer bloomFilter.Release()
+	if err := os.Remove(bloomFilter.fileName); err != nil {
+		return errors.New(fmt.Sprintf("error while deleting the bloom filter file %v, %v", bloomFilter.fileName, err))
+	}
+	return nil
+}
+
 func (bloomFilters *BloomFilters) Close() {
+	bloomFilters.lock.Lock()
+	defer bloomFilters.lock.Unlock()
+
 	for _, bloomFilter := range bloomFilters.filters {
 		bloomFilter.Close()
 	}
 }
 
 func (bloomFilters *BloomFilters) Has(key model.Slice) bool {
+	bloomFilters.lock.Lock()
+	defer bloomFilters.lock.Unlock()
+
 	for _, bloomFilter := range bloomFilters.filters {
 		if bloomFilter.Has(key) {
 			return true
@@ -76,40 +103,23 @@ func (bloomFilters *BloomFilters) Has(key model.Slice) bool {
 	return false
 }
 
+// init reloads every bloom filter from the header of its file, the name of the file plays no part in reloading it.
 func (bloomFilters *BloomFilters) init() error {
-	allBloomFilterFiles := func() ([]fs.FileInfo, error) {
-		bloomFiles, err := ioutil.ReadDir(bloomFilters.directory)
-		if err != nil {
-			return nil, err
-		}
-		return bloomFiles, nil
+	bloomFilterFiles, err := ioutil.ReadDir(bloomFilters.directory)
+	if err != nil {
+		return err
 	}
-	parseFileName := func(file fs.FileInfo) BloomFilterOptions {
-		fileName := strings.TrimSuffix(file.Name(), path.Ext(file.Name()))
-		nameParts := strings.Split(fileName, "_")
-		prefix := nameParts[0]
-		capacity, _ := strconv.Atoi(nameParts[1])
-		dataSize, _ := strconv.Atoi(nameParts[2])
-
-		return BloomFilterOptions{
-			FileNamePrefix: prefix,
-			Capacity:       capacity,
-			DataSize:       dataSize,
+	for _, file := range bloomFilterFiles {
+		if file.IsDir() || path.Ext(file.Name()) != ".bloom" {
+			continue
 		}
-	}
-	reloadAllBloomFilters := func() error {
-		bloomFilterFiles, err := allBloomFilterFiles()
+		filter, err := openBloomFilter(path.Join(bloomFilters.directory, file.Name()))
 		if err != nil {
 			return err
 		}
-		for _, file := range bloomFilterFiles {
-			if _, err := bloomFilters.NewBloomFilter(parseFileName(file)); err != nil {
-				return err
-			}
-		}
-		return nil
+		bloomFilters.filters = append(bloomFilters.filters, filter)
 	}
-	return reloadAllBloomFilters()
+	return nil
 }
 
 func (bloomFilters *BloomFilters) bloomFilterFileName(options BloomFilterOptions) string {
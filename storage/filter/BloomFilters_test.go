@@ -110,3 +110,80 @@ func TestAddsAKeyWithMultipleBloomFiltersAndChecksForItsPositiveExistenceSimulat
 
 //https://www.codementor.io/@arpitbhayani/the-rum-conjecture-16z2ckqte9
 //https://segmentfault.com/a/1190000041198407/en
+
+func TestKeepsARemovedBloomFilterUsableTillItsLastReferenceIsReleased(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	bloomFilters, _ := NewBloomFilters(directory, 0.001)
+	bloomFilter, _ := bloomFilters.NewBloomFilter(BloomFilterOptions{
+		Capacity:       1,
+		FileNamePrefix: "1",
+	})
+	key := model.NewSlice([]byte("Company"))
+	_ = bloomFilter.Put(key)
+
+	bloomFilter.Acquire()
+	_ = bloomFilters.Remove(bloomFilter)
+
+	if _, err := os.Stat(bloomFilter.FileName()); err == nil {
+		t.Fatalf("Expected the file of the removed bloom filter %v to be deleted", bloomFilter.FileName())
+	}
+	if bloomFilters.Has(key) {
+		t.Fatalf("Expected the removed bloom filter not to be searched by bloom filters")
+	}
+	if !bloomFilter.Has(key) {
+		t.Fatalf("Expected %v key to be present in the removed bloom filter while a reference is held", key.AsString())
+	}
+	bloomFilter.Release()
+}
+
+func TestReloadsABloomFilterFromItsHeaderEvenIfTheFalsePositiveRateChanges(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	bloomFilters, _ := NewBloomFilters(directory, 0.001)
+	bloomFilter, _ := bloomFilters.NewBloomFilter(BloomFilterOptions{
+		Capacity:       100,
+		FileNamePrefix: "1",
+	})
+	for count := 1; count <= 100; count++ {
+		_ = bloomFilter.Put(model.NewSlice([]byte("Key-" + strconv.Itoa(count))))
+	}
+	stat, _ := os.Stat(bloomFilter.FileName())
+	bloomFilters.Close()
+
+	bloomFiltersAfterRestart, err := NewBloomFilters(directory, 0.1)
+	if err != nil {
+		t.Fatalf("Expected the bloom filters to be reloaded, received error %v", err)
+	}
+	if statAfterRestart, _ := os.Stat(bloomFilter.FileName()); statAfterRestart.Size() != stat.Size() {
+		t.Fatalf("Expected the size of the reloaded bloom filter file to be %v, received %v", stat.Size(), statAfterRestart.Size())
+	}
+	for count := 1; count <= 100; count++ {
+		key := model.NewSlice([]byte("Key-" + strconv.Itoa(count)))
+		if bloomFiltersAfterRestart.Has(key) == false {
+			t.Fatalf("Expected key %v to be present but was not", key.AsString())
+		}
+	}
+}
+
+func TestRejectsABloomFilterFileWithACorruptedHeader(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	bloomFilters, _ := NewBloomFilters(directory, 0.001)
+	bloomFilter, _ := bloomFilters.NewBloomFilter(BloomFilterOptions{
+		Capacity:       1,
+		FileNamePrefix: "1",
+	})
+	bloomFilters.Close()
+
+	file, _ := os.OpenFile(bloomFilter.FileName(), os.O_RDWR, 0644)
+	_, _ = file.WriteAt([]byte{0xFF}, 5)
+	_ = file.Close()
+
+	if _, err := NewBloomFilters(directory, 0.001); err == nil {
+		t.Fatalf("Expected an error while reloading a bloom filter file with a corrupted header")
+	}
+}
@@ -0,0 +1,70 @@
+package filter
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+const (
+	currentFormatVersion uint32 = 1
+	headerSize                  = 40
+)
+
+var bigEndian = binary.BigEndian
+
+// Header is the fixed size head of a bloom filter file which records every parameter needed to reload the bloom filter,
+// so that a reloaded bloom filter hashes keys exactly like the bloom filter which wrote the file.
+type Header struct {
+	FormatVersion         uint32
+	Capacity              uint64
+	BitVectorSize         uint64
+	NumberOfHashFunctions uint32
+	BitsPerHashFunction   uint64
+	Seed                  uint32
+}
+
+func (header Header) Write(store *Store) {
+	store.WriteAt(header.encode(), 0)
+}
+
+func (header Header) encode() []byte {
+	//The way header is encoded is: 4 bytes for format version | 8 bytes for capacity | 8 bytes for bit vector size | 4 bytes for number of hash functions | 8 bytes for bits per hash function | 4 bytes for seed | 4 bytes for checksum of the preceding bytes
+	bytes := make([]byte, headerSize)
+	bigEndian.PutUint32(bytes, header.FormatVersion)
+	bigEndian.PutUint64(bytes[4:], header.Capacity)
+	bigEndian.PutUint64(bytes[12:], header.BitVectorSize)
+	bigEndian.PutUint32(bytes[20:], header.NumberOfHashFunctions)
+	bigEndian.PutUint64(bytes[24:], header.BitsPerHashFunction)
+	bigEndian.PutUint32(bytes[32:], header.Seed)
+	bigEndian.PutUint32(bytes[36:], crc32.ChecksumIEEE(bytes[:36]))
+	return bytes
+}
+
+// ReadHeader reads and verifies the header of the bloom filter file held by the store.
+func ReadHeader(store *Store) (Header, error) {
+	fileName := store.file.Name()
+	if store.Size() < headerSize {
+		return Header{}, errors.New(fmt.Sprintf("bloom filter file %v of size %v is smaller than the header", fileName, store.Size()))
+	}
+	bytes := store.ReadAt(0, headerSize)
+	if checksum := bigEndian.Uint32(bytes[36:]); checksum != crc32.ChecksumIEEE(bytes[:36]) {
+		return Header{}, errors.New(fmt.Sprintf("bloom filter file %v has a corrupted header, checksum %x does not match", fileName, checksum))
+	}
+	header := Header{
+		FormatVersion:         bigEndian.Uint32(bytes),
+		Capacity:              bigEndian.Uint64(bytes[4:]),
+		BitVectorSize:         bigEndian.Uint64(bytes[12:]),
+		NumberOfHashFunctions: bigEndian.Uint32(bytes[20:]),
+		BitsPerHashFunction:   bigEndian.Uint64(bytes[24:]),
+		Seed:                  bigEndian.Uint32(bytes[32:]),
+	}
+	if header.FormatVersion != currentFormatVersion {
+		return Header{}, errors.New(fmt.Sprintf("bloom filter file %v has an unsupported format version %v", fileName, header.FormatVersion))
+	}
+	if uint64(store.Size()-headerSize) < header.BitVectorSize {
+		return Header{}, errors.New(fmt.Sprintf("bloom filter file %v of size %v is smaller than its bit vector of size %v", fileName, store.Size(), header.BitVectorSize))
+	}
+	return header, nil
+}
@@ -4,11 +4,13 @@ import (
 	"github.com/edsrzf/mmap-go"
 	"log"
 	"os"
+	"sync"
 )
 
 type Store struct {
 	file               *os.File
 	memoryMappedRegion mmap.MMap
+	closeOnce          sync.Once
 }
 
 func NewStore(filePath string, size int) (*Store, error) {
@@ -25,6 +27,25 @@ func NewStore(filePath string, size int) (*Store, error) {
 	}
 }
 
+// OpenStore memory maps the whole of an existing file without resizing it.
+func OpenStore(filePath string) (*Store, error) {
+	file, err := os.OpenFile(filePath, os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	memoryMappedRegion, err := mmap.MapRegion(file, int(stat.Size()), mmap.RDWR, 0, 0)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &Store{file: file, memoryMappedRegion: memoryMappedRegion}, nil
+}
+
 func (store *Store) memoryMap(size int) (mmap.MMap, error) {
 	if err := store.file.Truncate(int64(size)); err != nil {
 		return nil, err
@@ -44,13 +65,26 @@ func (store *Store) GetByte(index uint64) byte {
 	return store.memoryMappedRegion[index]
 }
 
+func (store *Store) WriteAt(bytes []byte, offset int) {
+	copy(store.memoryMappedRegion[offset:], bytes)
+}
+
+func (store *Store) ReadAt(offset int, size int) []byte {
+	return store.memoryMappedRegion[offset : offset+size]
+}
+
 func (store *Store) Size() int {
 	return len(store.memoryMappedRegion)
 }
 
+// Close unmaps the memory mapped region and closes the file, closing an already closed store does nothing.
 func (store *Store) Close() {
-	err := store.file.Close()
-	if err != nil {
-		log.Default().Println("Error while closing the file " + store.file.Name())
-	}
+	store.closeOnce.Do(func() {
+		if err := store.memoryMappedRegion.Unmap(); err != nil {
+			log.Default().Println("Error while unmapping the file " + store.file.Name())
+		}
+		if err := store.file.Close(); err != nil {
+			log.Default().Println("Error while closing the file " + store.file.Name())
+		}
+	})
 }
@@ -0,0 +1,17 @@
+package iterator
+
+import "storage-engine-workshop/db/model"
+
+type Iterator interface {
+	SeekToFirst()
+	SeekToLast()
+	Seek(key model.Slice)
+	SeekForPrev(key model.Slice)
+	Next()
+	Prev()
+	Valid() bool
+	Key() model.Slice
+	Value() model.Slice
+	ExpiresAt() int64
+	Close()
+}
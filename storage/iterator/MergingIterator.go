@@ -0,0 +1,143 @@
+package iterator
+
+import (
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+)
+
+const (
+	forward int = iota
+	reverse
+)
+
+// MergingIterator expects its children ordered from the newest to the oldest source,
+// a key present in more than one child is returned once, from the newest child.
+// Moving forward, every valid child is positioned at or after the current key and moving in reverse,
+// at or before it. Changing the direction repositions all the children around the current key.
+type MergingIterator struct {
+	children      []Iterator
+	keyComparator comparator.KeyComparator
+	current       Iterator
+	direction     int
+}
+
+func NewMergingIterator(children []Iterator, keyComparator comparator.KeyComparator) *MergingIterator {
+	return &MergingIterator{
+		children:      children,
+		keyComparator: keyComparator,
+		direction:     forward,
+	}
+}
+
+func (mergingIterator *MergingIterator) SeekToFirst() {
+	for _, child := range mergingIterator.children {
+		child.SeekToFirst()
+	}
+	mergingIterator.direction = forward
+	mergingIterator.findSmallest()
+}
+
+func (mergingIterator *MergingIterator) SeekToLast() {
+	for _, child := range mergingIterator.children {
+		child.SeekToLast()
+	}
+	mergingIterator.direction = reverse
+	mergingIterator.findLargest()
+}
+
+func (mergingIterator *MergingIterator) Seek(key model.Slice) {
+	for _, child := range mergingIterator.children {
+		child.Seek(key)
+	}
+	mergingIterator.direction = forward
+	mergingIterator.findSmallest()
+}
+
+func (mergingIterator *MergingIterator) SeekForPrev(key model.Slice) {
+	for _, child := range mergingIterator.children {
+		child.SeekForPrev(key)
+	}
+	mergingIterator.direction = reverse
+	mergingIterator.findLargest()
+}
+
+func (mergingIterator *MergingIterator) Next() {
+	key := mergingIterator.current.Key()
+	if mergingIterator.direction == reverse {
+		for _, child := range mergingIterator.children {
+			child.Seek(key)
+		}
+		mergingIterator.direction = forward
+	}
+	for _, child := range mergingIterator.children {
+		if child.Valid() && mergingIterator.keyComparator.Compare(child.Key(), key) == 0 {
+			child.Next()
+		}
+	}
+	mergingIterator.findSmallest()
+}
+
+func (mergingIterator *MergingIterator) Prev() {
+	key := mergingIterator.current.Key()
+	if mergingIterator.direction == forward {
+		for _, child := range mergingIterator.children {
+			child.SeekForPrev(key)
+		}
+		mergingIterator.direction = reverse
+	}
+	for _, child := range mergingIterator.children {
+		if child.Valid() && mergingIterator.keyComparator.Compare(child.Key(), key) == 0 {
+			child.Prev()
+		}
+	}
+	mergingIterator.findLargest()
+}
+
+func (mergingIterator *MergingIterator) Valid() bool {
+	return mergingIterator.current != nil
+}
+
+func (mergingIterator *MergingIterator) Key() model.Slice {
+	return mergingIterator.current.Key()
+}
+
+func (mergingIterator *MergingIterator) Value() model.Slice {
+	return mergingIterator.current.Value()
+}
+
+func (mergingIterator *MergingIterator) ExpiresAt() int64 {
+	return mergingIterator.current.ExpiresAt()
+}
+
+func (mergingIterator *MergingIterator) Close() {
+	for _, child := range mergingIterator.children {
+		child.Close()
+	}
+	mergingIterator.current = nil
+}
+
+func (mergingIterator *MergingIterator) findSmallest() {
+	var smallest Iterator
+	for _, child := range mergingIterator.children {
+		if !child.Valid() {
+			continue
+		}
+		if smallest == nil || mergingIterator.keyComparator.Compare(child.Key(), smallest.Key()) < 0 {
+			smallest = child
+		}
+	}
+	mergingIterator.current = smallest
+}
+
+func (mergingIterator *MergingIterator) findLargest() {
+	var largest Iterator
+	for _, child := range mergingIterator.children {
+		if !child.Valid() {
+			continue
+		}
+		if largest == nil || mergingIterator.keyComparator.Compare(child.Key(), largest.Key()) > 0 {
+			largest = child
+		}
+	}
+	mergingIterator.current = largest
+}
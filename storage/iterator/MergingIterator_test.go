@@ -0,0 +1,118 @@
+package iterator
+
+import (
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"testing"
+)
+
+func sliceIteratorOf(keyValues ...string) *SliceIterator {
+	var pairs []model.KeyValuePair
+	for index := 0; index < len(keyValues); index = index + 2 {
+		pairs = append(pairs, model.KeyValuePair{Key: model.NewSlice([]byte(keyValues[index])), Value: model.NewSlice([]byte(keyValues[index+1]))})
+	}
+	return NewSliceIterator(pairs, comparator.StringKeyComparator{})
+}
+
+func TestMergesIteratorsInKeyOrder(t *testing.T) {
+	mergingIterator := NewMergingIterator([]Iterator{
+		sliceIteratorOf("B", "b", "D", "d"),
+		sliceIteratorOf("A", "a", "C", "c", "E", "e"),
+	}, comparator.StringKeyComparator{})
+
+	var keys string
+	for mergingIterator.SeekToFirst(); mergingIterator.Valid(); mergingIterator.Next() {
+		keys = keys + mergingIterator.Key().AsString()
+	}
+	if keys != "ABCDE" {
+		t.Fatalf("Expected keys to be %v, received %v", "ABCDE", keys)
+	}
+}
+
+func TestMergesIteratorsWithNewerIteratorShadowingOlder(t *testing.T) {
+	mergingIterator := NewMergingIterator([]Iterator{
+		sliceIteratorOf("B", "newer"),
+		sliceIteratorOf("A", "a", "B", "older", "C", "c"),
+	}, comparator.StringKeyComparator{})
+
+	var values []string
+	for mergingIterator.SeekToFirst(); mergingIterator.Valid(); mergingIterator.Next() {
+		values = append(values, mergingIterator.Value().AsString())
+	}
+	if len(values) != 3 || values[1] != "newer" {
+		t.Fatalf("Expected 3 values with %v in the middle, received %v", "newer", values)
+	}
+}
+
+func TestSeeksInMergedIterators(t *testing.T) {
+	mergingIterator := NewMergingIterator([]Iterator{
+		sliceIteratorOf("B", "b", "D", "d"),
+		sliceIteratorOf("A", "a", "C", "c"),
+	}, comparator.StringKeyComparator{})
+
+	mergingIterator.Seek(model.NewSlice([]byte("BB")))
+	if !mergingIterator.Valid() || mergingIterator.Key().AsString() != "C" {
+		t.Fatalf("Expected seek to position at %v", "C")
+	}
+}
+
+func TestIteratesMergedIteratorsInReverseWithNewerIteratorShadowingOlder(t *testing.T) {
+	mergingIterator := NewMergingIterator([]Iterator{
+		sliceIteratorOf("B", "newer", "D", "d"),
+		sliceIteratorOf("A", "a", "B", "older", "C", "c"),
+	}, comparator.StringKeyComparator{})
+
+	var keys, values string
+	for mergingIterator.SeekToLast(); mergingIterator.Valid(); mergingIterator.Prev() {
+		keys = keys + mergingIterator.Key().AsString()
+		values = values + mergingIterator.Value().AsString()
+	}
+	if keys != "DCBA" {
+		t.Fatalf("Expected keys to be %v, received %v", "DCBA", keys)
+	}
+	if values != "dcnewera" {
+		t.Fatalf("Expected values to be %v, received %v", "dcnewera", values)
+	}
+}
+
+func TestSeeksForPrevInMergedIterators(t *testing.T) {
+	mergingIterator := NewMergingIterator([]Iterator{
+		sliceIteratorOf("B", "b", "D", "d"),
+		sliceIteratorOf("A", "a", "C", "c"),
+	}, comparator.StringKeyComparator{})
+
+	mergingIterator.SeekForPrev(model.NewSlice([]byte("CC")))
+	if !mergingIterator.Valid() || mergingIterator.Key().AsString() != "C" {
+		t.Fatalf("Expected seek for prev to position at %v", "C")
+	}
+	mergingIterator.SeekForPrev(model.NewSlice([]byte("0")))
+	if mergingIterator.Valid() {
+		t.Fatalf("Expected seek for prev before the first key to leave the iterator invalid but was valid at %v", mergingIterator.Key().AsString())
+	}
+}
+
+func TestSwitchesDirectionInMergedIterators(t *testing.T) {
+	mergingIterator := NewMergingIterator([]Iterator{
+		sliceIteratorOf("B", "newer", "D", "d"),
+		sliceIteratorOf("A", "a", "B", "older", "C", "c"),
+	}, comparator.StringKeyComparator{})
+
+	var keys string
+	mergingIterator.Seek(model.NewSlice([]byte("B")))
+	keys = keys + mergingIterator.Key().AsString()
+	mergingIterator.Next()
+	keys = keys + mergingIterator.Key().AsString()
+	mergingIterator.Prev()
+	keys = keys + mergingIterator.Key().AsString()
+	mergingIterator.Prev()
+	keys = keys + mergingIterator.Key().AsString()
+	mergingIterator.Next()
+	keys = keys + mergingIterator.Key().AsString()
+
+	if keys != "BCBAB" {
+		t.Fatalf("Expected keys to be %v, received %v", "BCBAB", keys)
+	}
+	if mergingIterator.Value().AsString() != "newer" {
+		t.Fatalf("Expected value to be %v, received %v", "newer", mergingIterator.Value().AsString())
+	}
+}
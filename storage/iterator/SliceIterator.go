@@ -0,0 +1,70 @@
+package iterator
+
+import (
+	"sort"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+)
+
+type SliceIterator struct {
+	keyValuePairs []model.KeyValuePair
+	keyComparator comparator.KeyComparator
+	position      int
+}
+
+func NewSliceIterator(sortedKeyValuePairs []model.KeyValuePair, keyComparator comparator.KeyComparator) *SliceIterator {
+	return &SliceIterator{
+		keyValuePairs: sortedKeyValuePairs,
+		keyComparator: keyComparator,
+		position:      len(sortedKeyValuePairs),
+	}
+}
+
+func (sliceIterator *SliceIterator) SeekToFirst() {
+	sliceIterator.position = 0
+}
+
+func (sliceIterator *SliceIterator) SeekToLast() {
+	sliceIterator.position = len(sliceIterator.keyValuePairs) - 1
+}
+
+func (sliceIterator *SliceIterator) Seek(key model.Slice) {
+	sliceIterator.position = sort.Search(len(sliceIterator.keyValuePairs), func(index int) bool {
+		return sliceIterator.keyComparator.Compare(sliceIterator.keyValuePairs[index].Key, key) >= 0
+	})
+}
+
+func (sliceIterator *SliceIterator) SeekForPrev(key model.Slice) {
+	sliceIterator.position = sort.Search(len(sliceIterator.keyValuePairs), func(index int) bool {
+		return sliceIterator.keyComparator.Compare(sliceIterator.keyValuePairs[index].Key, key) > 0
+	}) - 1
+}
+
+func (sliceIterator *SliceIterator) Next() {
+	sliceIterator.position = sliceIterator.position + 1
+}
+
+func (sliceIterator *SliceIterator) Prev() {
+	sliceIterator.position = sliceIterator.position - 1
+}
+
+func (sliceIterator *SliceIterator) Valid() bool {
+	return sliceIterator.position >= 0 && sliceIterator.position < len(sliceIterator.keyValuePairs)
+}
+
+func (sliceIterator *SliceIterator) Key() model.Slice {
+	return sliceIterator.keyValuePairs[sliceIterator.position].Key
+}
+
+func (sliceIterator *SliceIterator) Value() model.Slice {
+	return sliceIterator.keyValuePairs[sliceIterator.position].Value
+}
+
+func (sliceIterator *SliceIterator) ExpiresAt() int64 {
+	return sliceIterator.keyValuePairs[sliceIterator.position].ExpiresAt
+}
+
+func (sliceIterator *SliceIterator) Close() {
+	sliceIterator.keyValuePairs = nil
+	sliceIterator.position = 0
+}
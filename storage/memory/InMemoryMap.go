@@ -7,31 +7,35 @@ import (
 )
 
 type InMemoryMap struct {
-	keyValues map[string]model.Slice
+	keyValues map[string]model.KeyValuePair
 }
 
 func NewInMemoryMap() *InMemoryMap {
 	return &InMemoryMap{
-		keyValues: make(map[string]model.Slice),
+		keyValues: make(map[string]model.KeyValuePair),
 	}
 }
 
 func (inMemoryMap *InMemoryMap) Put(key model.Slice, value model.Slice) bool {
+	return inMemoryMap.PutWithExpiry(key, value, model.NeverExpires)
+}
+
+// PutWithExpiry replaces the pair of a key which is already present, be it live or expired, and answers false in that case.
+func (inMemoryMap *InMemoryMap) PutWithExpiry(key model.Slice, value model.Slice, expiresAt int64) bool {
 	keyAsString := key.AsString()
-	if _, ok := inMemoryMap.keyValues[keyAsString]; ok {
-		return false
-	}
-	inMemoryMap.keyValues[keyAsString] = value
-	return true
+	_, present := inMemoryMap.keyValues[keyAsString]
+	inMemoryMap.keyValues[keyAsString] = model.KeyValuePair{Key: key, Value: value, ExpiresAt: expiresAt}
+	return !present
 }
 
 func (inMemoryMap *InMemoryMap) Get(key model.Slice) model.GetResult {
 	keyAsString := key.AsString()
-	if value, ok := inMemoryMap.keyValues[keyAsString]; ok {
+	if keyValuePair, ok := inMemoryMap.keyValues[keyAsString]; ok {
 		return model.GetResult{
-			Key:    key,
-			Value:  value,
-			Exists: true,
+			Key:       key,
+			Value:     keyValuePair.Value,
+			Exists:    true,
+			ExpiresAt: keyValuePair.ExpiresAt,
 		}
 	}
 	return model.GetResult{
@@ -58,8 +62,8 @@ func (inMemoryMap *InMemoryMap) MultiGet(keys []model.Slice) (model.MultiGetResu
 
 func (inMemoryMap *InMemoryMap) AllKeyValues(keyComparator comparator.KeyComparator) []model.KeyValuePair {
 	var pairs []model.KeyValuePair
-	for key, value := range inMemoryMap.keyValues {
-		pairs = append(pairs, model.KeyValuePair{Key: model.NewSlice([]byte(key)), Value: value})
+	for _, keyValuePair := range inMemoryMap.keyValues {
+		pairs = append(pairs, keyValuePair)
 	}
 
 	sort.SliceStable(pairs, func(i, j int) bool {
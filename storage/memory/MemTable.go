@@ -3,16 +3,22 @@ package memory
 import (
 	"storage-engine-workshop/db/model"
 	"storage-engine-workshop/storage/comparator"
+	"storage-engine-workshop/storage/iterator"
 	"storage-engine-workshop/storage/utils"
+	"sync"
 )
 
+// MemTable allows a single writer along with concurrent readers.
 type MemTable struct {
 	//head           *Node
-	inMemoryMap    *InMemoryMap
-	size           uint64
-	totalKeys      int
-	keyComparator  comparator.KeyComparator
-	levelGenerator utils.LevelGenerator
+	inMemoryMap      *InMemoryMap
+	size             uint64
+	totalKeys        int
+	smallestSequence uint64
+	largestSequence  uint64
+	keyComparator    comparator.KeyComparator
+	levelGenerator   utils.LevelGenerator
+	lock             sync.RWMutex
 }
 
 func NewMemTable(maxLevel int, keyComparator comparator.KeyComparator) *MemTable {
@@ -26,30 +32,83 @@ func NewMemTable(maxLevel int, keyComparator comparator.KeyComparator) *MemTable
 }
 
 func (memTable *MemTable) Put(key, value model.Slice) bool {
-	if ok := memTable.inMemoryMap.Put(key, value); ok {
+	return memTable.PutWithExpiry(key, value, model.NeverExpires)
+}
+
+// PutWithExpiry replaces the value and the expiry of a key already in the memTable, it answers false in that case.
+func (memTable *MemTable) PutWithExpiry(key, value model.Slice, expiresAt int64) bool {
+	memTable.lock.Lock()
+	defer memTable.lock.Unlock()
+
+	replaced := memTable.inMemoryMap.Get(key)
+	if ok := memTable.inMemoryMap.PutWithExpiry(key, value, expiresAt); ok {
 		memTable.size = memTable.size + uint64(key.Size()) + uint64(value.Size())
 		memTable.totalKeys = memTable.totalKeys + 1
 		return ok
 	}
+	memTable.size = memTable.size - uint64(replaced.Value.Size()) + uint64(value.Size())
 	return false
 }
 
+// RecordSequence widens the range of the WAL sequences whose key/value pairs are in the memTable.
+func (memTable *MemTable) RecordSequence(sequence uint64) {
+	memTable.lock.Lock()
+	defer memTable.lock.Unlock()
+
+	if memTable.smallestSequence == 0 || sequence < memTable.smallestSequence {
+		memTable.smallestSequence = sequence
+	}
+	if sequence > memTable.largestSequence {
+		memTable.largestSequence = sequence
+	}
+}
+
+func (memTable *MemTable) SequenceRange() (uint64, uint64) {
+	memTable.lock.RLock()
+	defer memTable.lock.RUnlock()
+
+	return memTable.smallestSequence, memTable.largestSequence
+}
+
+func (memTable *MemTable) KeyComparator() comparator.KeyComparator {
+	return memTable.keyComparator
+}
+
 func (memTable *MemTable) Get(key model.Slice) model.GetResult {
+	memTable.lock.RLock()
+	defer memTable.lock.RUnlock()
+
 	return memTable.inMemoryMap.Get(key)
 }
 
 func (memTable *MemTable) MultiGet(keys []model.Slice) (model.MultiGetResult, []model.Slice) {
+	memTable.lock.RLock()
+	defer memTable.lock.RUnlock()
+
 	return memTable.inMemoryMap.MultiGet(keys)
 }
 
 func (memTable *MemTable) AllKeyValues() []model.KeyValuePair {
+	memTable.lock.RLock()
+	defer memTable.lock.RUnlock()
+
 	return memTable.inMemoryMap.AllKeyValues(memTable.keyComparator)
 }
 
+func (memTable *MemTable) NewIterator() iterator.Iterator {
+	return iterator.NewSliceIterator(memTable.AllKeyValues(), memTable.keyComparator)
+}
+
 func (memTable *MemTable) TotalSize() uint64 {
+	memTable.lock.RLock()
+	defer memTable.lock.RUnlock()
+
 	return memTable.size
 }
 
 func (memTable *MemTable) TotalKeys() int {
+	memTable.lock.RLock()
+	defer memTable.lock.RUnlock()
+
 	return memTable.totalKeys
 }
@@ -104,3 +104,50 @@ func TestReturnsTheTotalMemTableSize(t *testing.T) {
 		t.Fatalf("Expected total memtable size to be %v, received %v", expected, size)
 	}
 }
+
+func TestPutAKeyValueWithExpiryAndGetsItsExpiryInMemTable(t *testing.T) {
+	memTable := NewMemTable(10, comparator.StringKeyComparator{})
+	key := model.NewSlice([]byte("HDD"))
+	value := model.NewSlice([]byte("Hard disk"))
+	memTable.PutWithExpiry(key, value, 1000)
+
+	getResult := memTable.Get(key)
+	if getResult.ExpiresAt != 1000 {
+		t.Fatalf("Expected expiresAt to be %v, received %v", 1000, getResult.ExpiresAt)
+	}
+	if keyValuePairs := memTable.AllKeyValues(); keyValuePairs[0].ExpiresAt != 1000 {
+		t.Fatalf("Expected expiresAt to be %v from all keys but received %v", 1000, keyValuePairs[0].ExpiresAt)
+	}
+}
+
+func TestReplacesAnExpiredKeyValueAndAdjustsTheSizeOfMemTable(t *testing.T) {
+	memTable := NewMemTable(10, comparator.StringKeyComparator{})
+	key := model.NewSlice([]byte("Session"))
+	memTable.PutWithExpiry(key, model.NewSlice([]byte("Expired token")), 1000)
+	memTable.PutWithExpiry(key, model.NewSlice([]byte("Token")), model.NeverExpires)
+
+	if getResult := memTable.Get(key); getResult.Value.AsString() != "Token" || getResult.ExpiresAt != model.NeverExpires {
+		t.Fatalf("Expected value to be %v without expiry, received %v expiring at %v", "Token", getResult.Value.AsString(), getResult.ExpiresAt)
+	}
+	if expected := uint64(len("Session") + len("Token")); memTable.TotalSize() != expected || memTable.TotalKeys() != 1 {
+		t.Fatalf("Expected %v key of total size %v, received %v keys of total size %v", 1, expected, memTable.TotalKeys(), memTable.TotalSize())
+	}
+}
+
+func TestIteratesOverMemTableInKeyOrder(t *testing.T) {
+	memTable := NewMemTable(10, comparator.StringKeyComparator{})
+	memTable.Put(model.NewSlice([]byte("SDD")), model.NewSlice([]byte("Solid state")))
+	memTable.Put(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk")))
+
+	memTableIterator := memTable.NewIterator()
+	defer memTableIterator.Close()
+
+	memTableIterator.SeekToFirst()
+	if memTableIterator.Key().AsString() != "HDD" {
+		t.Fatalf("Expected first key to be %v, received %v", "HDD", memTableIterator.Key().AsString())
+	}
+	memTableIterator.Next()
+	if memTableIterator.Key().AsString() != "SDD" {
+		t.Fatalf("Expected second key to be %v, received %v", "SDD", memTableIterator.Key().AsString())
+	}
+}
@@ -54,7 +54,9 @@ func (node *Node) Get(key model.Slice, keyComparator comparator.KeyComparator) m
 	return model.GetResult{Key: key, Value: model.NilSlice(), Exists: false}
 }
 
-func (node *Node) MultiGet(keys []model.Slice, keyComparator comparator.KeyComparator) (model.MultiGetResult, []model.Slice) {
+func (node *Node) MultiGet(unsortedKeys []model.Slice, keyComparator comparator.KeyComparator) (model.MultiGetResult, []model.Slice) {
+	keys := make([]model.Slice, len(unsortedKeys))
+	copy(keys, unsortedKeys)
 	sort.SliceStable(keys, func(i, j int) bool {
 		return keyComparator.Compare(keys[i], keys[j]) < 0
 	})
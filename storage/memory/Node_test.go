@@ -125,3 +125,22 @@ func TestGetsAllKeyValues(t *testing.T) {
 		t.Fatalf("Expected persistent value to be %v received %v", value.AsString(), keyValuePairs[0].Value.AsString())
 	}
 }
+
+func TestDoesMultiGetByKeysInNodeWithoutSortingTheKeysOfTheCaller(t *testing.T) {
+	const maxLevel = 8
+	keyComparator := comparator.StringKeyComparator{}
+
+	sentinelNode := NewNode(model.NilSlice(), model.NilSlice(), maxLevel)
+	sentinelNode.Put(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk")), keyComparator, utils.NewLevelGenerator(maxLevel))
+	sentinelNode.Put(model.NewSlice([]byte("SDD")), model.NewSlice([]byte("Solid state")), keyComparator, utils.NewLevelGenerator(maxLevel))
+
+	keys := []model.Slice{
+		model.NewSlice([]byte("SDD")),
+		model.NewSlice([]byte("HDD")),
+	}
+	_, _ = sentinelNode.MultiGet(keys, keyComparator)
+
+	if keys[0].AsString() != "SDD" || keys[1].AsString() != "HDD" {
+		t.Fatalf("Expected keys to remain %v, received %v", []string{"SDD", "HDD"}, []string{keys[0].AsString(), keys[1].AsString()})
+	}
+}
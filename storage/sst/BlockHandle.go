@@ -0,0 +1,26 @@
+package sst
+
+import "unsafe"
+
+var reservedBlockHandleSize = unsafe.Sizeof(uint64(0)) + unsafe.Sizeof(uint64(0))
+
+// BlockHandle locates a block in the ssTable file.
+type BlockHandle struct {
+	Offset int64
+	Size   int64
+}
+
+func decodeBlockHandle(bytes []byte) BlockHandle {
+	return BlockHandle{
+		Offset: int64(bigEndian.Uint64(bytes)),
+		Size:   int64(bigEndian.Uint64(bytes[unsafe.Sizeof(uint64(0)):])),
+	}
+}
+
+func (blockHandle BlockHandle) encode() []byte {
+	//The way blockHandle is encoded is: 8 bytes for offset | 8 bytes for size
+	bytes := make([]byte, reservedBlockHandleSize)
+	bigEndian.PutUint64(bytes, uint64(blockHandle.Offset))
+	bigEndian.PutUint64(bytes[unsafe.Sizeof(uint64(0)):], uint64(blockHandle.Size))
+	return bytes
+}
@@ -0,0 +1,101 @@
+package sst
+
+import (
+	"context"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+)
+
+// CompactRangeStatistics are the bytes of the ssTables read and written by CompactRange.
+type CompactRangeStatistics struct {
+	BytesRead    int64
+	BytesWritten int64
+}
+
+// CompactRange rewrites every ssTable which overlaps [lowerBound, upperBound), an empty bound leaves that side open.
+// The compaction style decides the compactions level by level, from level 0 down to the deepest level.
+// The rewritten ssTables keep only the newest version of every key, a tombstone is dropped once no ssTable outside the compaction may hold its key.
+// CompactRange stops once ctx is done, while it waits for a running compaction or in the middle of a compaction whose merged ssTables are deleted.
+// The compactions finished till then stay in place.
+func (ssTables *SSTables) CompactRange(ctx context.Context, lowerBound, upperBound model.Slice, keyComparator comparator.KeyComparator) (CompactRangeStatistics, error) {
+	var statistics CompactRangeStatistics
+	if err := ssTables.lockCompactions(ctx); err != nil {
+		return statistics, err
+	}
+	defer ssTables.unlockCompactions()
+
+	written := make(map[*SSTable]bool)
+	for level := 0; level < ssTables.totalLevels(); level++ {
+		if err := ctx.Err(); err != nil {
+			return statistics, err
+		}
+		compaction := ssTables.pickRangeCompaction(level, lowerBound, upperBound, keyComparator)
+		if compaction == nil {
+			continue
+		}
+		if compaction.outputLevel == level && allWritten(compaction.inputs, written) {
+			for _, input := range compaction.inputs {
+				input.release()
+			}
+			continue
+		}
+		outputs, err := ssTables.run(ctx, compaction, keyComparator)
+		if err != nil {
+			return statistics, err
+		}
+		for _, input := range compaction.inputs {
+			statistics.BytesRead = statistics.BytesRead + input.Size()
+		}
+		for _, output := range outputs {
+			written[output] = true
+			statistics.BytesWritten = statistics.BytesWritten + output.Size()
+		}
+	}
+	return statistics, nil
+}
+
+// allWritten answers true if the inputs of an in place compaction were all written by an earlier compaction of the range.
+func allWritten(inputs []*SSTable, written map[*SSTable]bool) bool {
+	for _, input := range inputs {
+		if !written[input] {
+			return false
+		}
+	}
+	return true
+}
+
+func (ssTables *SSTables) totalLevels() int {
+	ssTables.lock.RLock()
+	defer ssTables.lock.RUnlock()
+
+	return len(ssTables.levels)
+}
+
+func (ssTables *SSTables) pickRangeCompaction(level int, lowerBound, upperBound model.Slice, keyComparator comparator.KeyComparator) *compaction {
+	ssTables.lock.RLock()
+	defer ssTables.lock.RUnlock()
+
+	compaction := ssTables.options.compactionOrDefault().pickRange(ssTables.eligibleLevels(), level, lowerBound, upperBound, keyComparator)
+	return ssTables.withOlderTables(acquired(compaction))
+}
+
+// level0RunOverlapping returns the run of level 0 ssTables from the oldest to the newest ssTable which overlaps [lowerBound, upperBound),
+// the ssTables in between are part of the run so that the merged ssTable can take the place of the run.
+func level0RunOverlapping(levels [][]*SSTable, level int, lowerBound, upperBound model.Slice, keyComparator comparator.KeyComparator) *compaction {
+	if level != 0 {
+		return nil
+	}
+	begin, end := -1, -1
+	for index, table := range levels[0] {
+		if table.Overlaps(lowerBound, upperBound, keyComparator) {
+			if begin < 0 {
+				begin = index
+			}
+			end = index + 1
+		}
+	}
+	if begin < 0 {
+		return nil
+	}
+	return &compaction{inputs: newestFirst(levels[0][begin:end]), outputLevel: 0}
+}
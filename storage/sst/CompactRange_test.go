@@ -0,0 +1,182 @@
+package sst
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"storage-engine-workshop/storage/memory"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCompactsTheRunOfLevel0SSTablesOverlappingTheRange(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	outside := publishSSTableWith(ssTables, map[string]string{"A-1": "Value-A"})
+	var run []*SSTable
+	for _, keyValuePairs := range []map[string]string{{"Key-5": "Value-5"}, {"Z-1": "Value-Z"}, {"Key-5": "Value-55", "Key-6": "Value-6"}} {
+		run = append(run, publishSSTableWith(ssTables, keyValuePairs))
+	}
+
+	statistics, err := ssTables.CompactRange(context.Background(), model.NewSlice([]byte("Key-0")), model.NewSlice([]byte("Key-9")), comparator.StringKeyComparator{})
+	if err != nil {
+		t.Fatalf("Expected the range to be compacted, received error %v", err)
+	}
+	if len(ssTables.levels[0]) != 2 || ssTables.levels[0][0] != outside {
+		t.Fatalf("Expected the ssTable outside the range to be kept along with the compacted run, received %v ssTables", len(ssTables.levels[0]))
+	}
+	expectedBytesRead := run[0].Size() + run[1].Size() + run[2].Size()
+	if statistics.BytesRead != expectedBytesRead {
+		t.Fatalf("Expected %v bytes read, received %v", expectedBytesRead, statistics.BytesRead)
+	}
+	if statistics.BytesWritten != ssTables.levels[0][1].Size() {
+		t.Fatalf("Expected %v bytes written, received %v", ssTables.levels[0][1].Size(), statistics.BytesWritten)
+	}
+	for key, value := range map[string]string{"A-1": "Value-A", "Key-5": "Value-55", "Key-6": "Value-6", "Z-1": "Value-Z"} {
+		if getResult := ssTables.Get(model.NewSlice([]byte(key)), comparator.StringKeyComparator{}); getResult.Value.AsString() != value {
+			t.Fatalf("Expected value to be %v, received %v", value, getResult.Value.AsString())
+		}
+	}
+}
+
+func TestKeepsTombstonesWhileCompactingARunWhichAnOlderSSTableOutsideTheRangeShadows(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	publishSSTableWith(ssTables, map[string]string{"Orphan": "Child-1", "Session": "Old-Token"})
+	publishSSTableWith(ssTables, map[string]string{"Key-5": "Value-5"})
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTable.Put(model.NewSlice([]byte("Key-6")), model.NewSlice([]byte("Value-6")))
+	memTable.Put(model.NewSlice([]byte("Orphan")), model.NewSlice([]byte("Child-2")))
+	memTable.PutWithExpiry(model.NewSlice([]byte("Session")), model.NewSlice([]byte("Token")), model.ExpiresAfter(50*time.Millisecond))
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+	ssTables.AllowSearchIn(ssTable)
+	ssTables.options.CompactionFilter = CompactionFilterFunc(func(key, value model.Slice) (CompactionDecision, model.Slice) {
+		if key.AsString() == "Orphan" {
+			return RemoveKey, model.NilSlice()
+		}
+		return KeepValue, value
+	})
+	time.Sleep(60 * time.Millisecond)
+
+	if _, err := ssTables.CompactRange(context.Background(), model.NewSlice([]byte("Key-0")), model.NewSlice([]byte("Key-9")), comparator.StringKeyComparator{}); err != nil {
+		t.Fatalf("Expected the range to be compacted, received error %v", err)
+	}
+	if len(ssTables.levels[0]) != 2 {
+		t.Fatalf("Expected the older ssTable outside the range to be kept along with the compacted run, received %v ssTables", len(ssTables.levels[0]))
+	}
+	for _, key := range []string{"Orphan", "Session"} {
+		if getResult := ssTables.Get(model.NewSlice([]byte(key)), comparator.StringKeyComparator{}); !getResult.IsExpiredAt(time.Now()) {
+			t.Fatalf("Expected a tombstone to shadow the older version of %v, received %v", key, getResult.Value.AsString())
+		}
+	}
+
+	if _, err := ssTables.CompactRange(context.Background(), model.NilSlice(), model.NilSlice(), comparator.StringKeyComparator{}); err != nil {
+		t.Fatalf("Expected the range to be compacted, received error %v", err)
+	}
+	if properties, _ := ssTables.levels[0][0].Properties(); len(ssTables.levels[0]) != 1 || properties.TotalEntries != 2 {
+		t.Fatalf("Expected the tombstones to be dropped once no older ssTable remains, received %v entries", properties.TotalEntries)
+	}
+}
+
+func TestCompactsTheRangeDownToTheDeepestLevelWithLeveledCompaction(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{Compaction: LeveledCompactionOptions{}})
+	for table := 1; table <= 3; table++ {
+		publishSSTableWith(ssTables, map[string]string{"Company": "TW-" + strconv.Itoa(table), "Key-" + strconv.Itoa(table): "Value-" + strconv.Itoa(table)})
+	}
+
+	if _, err := ssTables.CompactRange(context.Background(), model.NilSlice(), model.NilSlice(), comparator.StringKeyComparator{}); err != nil {
+		t.Fatalf("Expected the range to be compacted, received error %v", err)
+	}
+	if len(ssTables.levels[0]) != 0 || len(ssTables.levels[1]) != 1 {
+		t.Fatalf("Expected level 0 to be pushed into level 1, received %v ssTables in level 0 and %v in level 1", len(ssTables.levels[0]), len(ssTables.levels[1]))
+	}
+	if getResult := ssTables.Get(model.NewSlice([]byte("Company")), comparator.StringKeyComparator{}); getResult.Value.AsString() != "TW-3" {
+		t.Fatalf("Expected value to be %v, received %v", "TW-3", getResult.Value.AsString())
+	}
+
+	statistics, _ := ssTables.CompactRange(context.Background(), model.NilSlice(), model.NilSlice(), comparator.StringKeyComparator{})
+	if statistics.BytesRead == 0 || len(ssTables.levels[1]) != 1 {
+		t.Fatalf("Expected the deepest level to be rewritten in place, received %v bytes read and %v ssTables in level 1", statistics.BytesRead, len(ssTables.levels[1]))
+	}
+}
+
+func TestDoesNotCompactTheRangeOnceTheContextIsDone(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	for table := 1; table <= 2; table++ {
+		publishSSTableWith(ssTables, map[string]string{"Key-" + strconv.Itoa(table): "Value-" + strconv.Itoa(table)})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := ssTables.CompactRange(ctx, model.NilSlice(), model.NilSlice(), comparator.StringKeyComparator{}); err != context.Canceled {
+		t.Fatalf("Expected error %v, received %v", context.Canceled, err)
+	}
+	if len(ssTables.levels[0]) != 2 {
+		t.Fatalf("Expected %v ssTables to be left as they were, received %v", 2, len(ssTables.levels[0]))
+	}
+}
+
+func TestStopsACompactionOfTheRangeOnceTheContextIsDoneAndDeletesItsPartialOutput(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	compacting := false
+	cancelOnKey6 := CompactionFilterFunc(func(key, value model.Slice) (CompactionDecision, model.Slice) {
+		if compacting && key.AsString() == "Key-6" {
+			cancel()
+		}
+		return KeepValue, model.NilSlice()
+	})
+	ssTables, _ := NewSSTables(directory, SSTableOptions{CompactionFilter: cancelOnKey6})
+	inputs := []*SSTable{
+		publishSSTableWith(ssTables, map[string]string{"Key-5": "Value-5", "Key-7": "Value-7"}),
+		publishSSTableWith(ssTables, map[string]string{"Key-5": "Value-55", "Key-6": "Value-6"}),
+	}
+	files, _ := ioutil.ReadDir(ssTables.directory)
+	compacting = true
+
+	if _, err := ssTables.CompactRange(ctx, model.NilSlice(), model.NilSlice(), comparator.StringKeyComparator{}); err != context.Canceled {
+		t.Fatalf("Expected error %v, received %v", context.Canceled, err)
+	}
+	if len(ssTables.levels[0]) != 2 || ssTables.levels[0][0] != inputs[0] || ssTables.levels[0][1] != inputs[1] {
+		t.Fatalf("Expected the inputs of the stopped compaction to stay in place, received %v ssTables", len(ssTables.levels[0]))
+	}
+	if remainingFiles, _ := ioutil.ReadDir(ssTables.directory); len(remainingFiles) != len(files) {
+		t.Fatalf("Expected the partial output of the stopped compaction to be deleted, received %v files instead of %v", len(remainingFiles), len(files))
+	}
+	if getResult := ssTables.Get(model.NewSlice([]byte("Key-5")), comparator.StringKeyComparator{}); getResult.Value.AsString() != "Value-55" {
+		t.Fatalf("Expected value to be %v, received %v", "Value-55", getResult.Value.AsString())
+	}
+}
+
+func TestStopsWaitingForARunningCompactionOnceTheContextIsDone(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	publishSSTableWith(ssTables, map[string]string{"Key-5": "Value-5"})
+	_ = ssTables.lockCompactions(context.Background())
+	defer ssTables.unlockCompactions()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := ssTables.CompactRange(ctx, model.NilSlice(), model.NilSlice(), comparator.StringKeyComparator{}); err != context.DeadlineExceeded {
+		t.Fatalf("Expected error %v, received %v", context.DeadlineExceeded, err)
+	}
+}
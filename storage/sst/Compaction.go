@@ -0,0 +1,360 @@
+package sst
+
+import (
+	"context"
+	"sort"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"storage-engine-workshop/storage/iterator"
+	"time"
+)
+
+// CompactionStyle decides which ssTables are merged together and the level the merged ssTables are published in.
+// SizeTieredCompactionOptions, LeveledCompactionOptions, FIFOCompactionOptions and TimeWindowCompactionOptions are the available styles.
+// pickRange picks the compaction of the ssTables in the level which overlap [lowerBound, upperBound) for CompactRange
+// and backlog answers the number of ssTables waiting for a compaction, which the write stalls throttle the commits by.
+type CompactionStyle interface {
+	pick(levels [][]*SSTable, keyComparator comparator.KeyComparator) *compaction
+	pickRange(levels [][]*SSTable, level int, lowerBound, upperBound model.Slice, keyComparator comparator.KeyComparator) *compaction
+	backlog(levels [][]*SSTable) int
+}
+
+// agingCompactionStyle is a compaction style whose compactions also fall due as the ssTables age, without a flush to queue them.
+type agingCompactionStyle interface {
+	nextDueAt(levels [][]*SSTable) (time.Time, bool)
+}
+
+// compaction merges the inputs, ordered from the newest to the oldest, into ssTables of at most targetFileSize bytes in the outputLevel.
+// A targetFileSize of 0 merges the inputs into a single ssTable and dropInputs drops the inputs without merging them.
+// olderTables are the ssTables outside the inputs which may hold an older version of a key in the inputs,
+// a tombstone is dropped only if none of them may hold its key.
+type compaction struct {
+	inputs         []*SSTable
+	outputLevel    int
+	targetFileSize int64
+	dropInputs     bool
+	olderTables    []*SSTable
+}
+
+// Compact runs one compaction picked by the compaction style, the merged ssTables keep only the newest version of every key.
+// An expired newest version is kept as a tombstone unless no ssTable outside the inputs may hold an older version of the key.
+// The merged ssTables atomically replace the inputs, or the inputs are simply dropped if the compaction style drops them.
+// The files of the inputs are deleted once no snapshot or iterator references them.
+// It answers false if there was nothing to compact.
+func (ssTables *SSTables) Compact(keyComparator comparator.KeyComparator) (bool, error) {
+	ctx := context.Background()
+	if err := ssTables.lockCompactions(ctx); err != nil {
+		return false, err
+	}
+	defer ssTables.unlockCompactions()
+
+	compaction := ssTables.pickCompaction(keyComparator)
+	if compaction == nil {
+		return false, nil
+	}
+	if _, err := ssTables.run(ctx, compaction, keyComparator); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// lockCompactions lets one compaction run at a time, it gives up waiting for the running compaction once ctx is done.
+func (ssTables *SSTables) lockCompactions(ctx context.Context) error {
+	select {
+	case ssTables.compactionLock <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (ssTables *SSTables) unlockCompactions() {
+	<-ssTables.compactionLock
+}
+
+// run merges or drops the acquired inputs of the compaction and releases them, the inputs stay in place if ctx is done while they are merged.
+func (ssTables *SSTables) run(ctx context.Context, compaction *compaction, keyComparator comparator.KeyComparator) ([]*SSTable, error) {
+	defer func() {
+		for _, input := range compaction.inputs {
+			input.release()
+		}
+	}()
+	var outputs []*SSTable
+	if !compaction.dropInputs {
+		merged, err := ssTables.merge(ctx, compaction, keyComparator)
+		if err != nil {
+			return nil, err
+		}
+		outputs = merged
+	}
+	ssTables.replace(compaction, outputs, keyComparator)
+	return outputs, nil
+}
+
+func (ssTables *SSTables) pickCompaction(keyComparator comparator.KeyComparator) *compaction {
+	ssTables.lock.RLock()
+	defer ssTables.lock.RUnlock()
+
+	return ssTables.withOlderTables(acquired(ssTables.options.compactionOrDefault().pick(ssTables.eligibleLevels(), keyComparator)))
+}
+
+// NextCompactionDueAt answers the time a compaction falls due only because the ssTables age, if the compaction style has such compactions.
+func (ssTables *SSTables) NextCompactionDueAt() (time.Time, bool) {
+	agingStyle, ok := ssTables.options.compactionOrDefault().(agingCompactionStyle)
+	if !ok {
+		return time.Time{}, false
+	}
+	ssTables.lock.RLock()
+	defer ssTables.lock.RUnlock()
+
+	return agingStyle.nextDueAt(ssTables.eligibleLevels())
+}
+
+// eligibleLevels leaves out the level 0 ssTables which are not older than every ssTable still being flushed,
+// a compaction of such an ssTable could let an older ssTable, flushed later, shadow it.
+func (ssTables *SSTables) eligibleLevels() [][]*SSTable {
+	levels := make([][]*SSTable, len(ssTables.levels))
+	copy(levels, ssTables.levels)
+	for index, table := range levels[0] {
+		if !ssTables.isOlderThanAPendingFlush(table) {
+			levels[0] = levels[0][:index]
+			break
+		}
+	}
+	return levels
+}
+
+// acquired acquires the inputs of the compaction, it returns nil for a compaction without inputs.
+func acquired(compaction *compaction) *compaction {
+	if compaction == nil || len(compaction.inputs) == 0 {
+		return nil
+	}
+	for _, input := range compaction.inputs {
+		input.acquire()
+	}
+	return compaction
+}
+
+// withOlderTables expects the lock to be held, it collects the ssTables outside the inputs which are older than an input,
+// those are the ssTables in a level deeper than the shallowest input and, in level 0, those older than the newest level 0 input.
+// ssTables in the shallowest level, if it is not level 0, hold a disjoint key range which is newer than the inputs below it.
+func (ssTables *SSTables) withOlderTables(compaction *compaction) *compaction {
+	if compaction == nil {
+		return nil
+	}
+	isInput := make(map[*SSTable]bool)
+	for _, input := range compaction.inputs {
+		isInput[input] = true
+	}
+	shallowestLevel, newestGeneration := len(ssTables.levels), -1
+	for level, tables := range ssTables.levels {
+		for _, table := range tables {
+			if !isInput[table] {
+				continue
+			}
+			if level < shallowestLevel {
+				shallowestLevel = level
+			}
+			if level == 0 && table.generation > newestGeneration {
+				newestGeneration = table.generation
+			}
+		}
+	}
+	for level, tables := range ssTables.levels {
+		for _, table := range tables {
+			if isInput[table] {
+				continue
+			}
+			if level > shallowestLevel || (level == 0 && table.generation < newestGeneration) {
+				compaction.olderTables = append(compaction.olderTables, table)
+			}
+		}
+	}
+	return compaction
+}
+
+// mayHoldAnOlderVersion answers true if an ssTable outside the inputs may hold an older version of the key, as per its key range and its bloom filter.
+func (compaction *compaction) mayHoldAnOlderVersion(key model.Slice, keyComparator comparator.KeyComparator) bool {
+	for _, table := range compaction.olderTables {
+		if table.MayContainKey(key, keyComparator) && table.bloomFilter.Has(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ssTables *SSTables) isOlderThanAPendingFlush(table *SSTable) bool {
+	for pendingFileId := range ssTables.pendingFileIds {
+		if pendingFileId < table.generation {
+			return false
+		}
+	}
+	return true
+}
+
+// merge streams the newest version of every key of the inputs, ordered from the newest to the oldest, through a merging iterator
+// into ssTables whose raw key and value size reaches targetFileSize, a targetFileSize of 0 writes a single ssTable.
+// The compaction filter sees only the newest unexpired version. An expired newest version or a key removed by the compaction filter
+// is written as a tombstone if an older ssTable may hold an older version of the key, else it is dropped.
+// There are no merged ssTables if every key/value pair of the inputs is dropped.
+// merge stops once ctx is done and deletes the ssTables it has written till then.
+func (ssTables *SSTables) merge(ctx context.Context, compaction *compaction, keyComparator comparator.KeyComparator) ([]*SSTable, error) {
+	remainingEntries, err := totalEntries(compaction.inputs)
+	if err != nil {
+		return nil, err
+	}
+	children := make([]iterator.Iterator, 0, len(compaction.inputs))
+	for _, input := range compaction.inputs {
+		children = append(children, input.NewIterator(keyComparator, ReadOptions{SkipFillingCache: true}))
+	}
+	mergingIterator := iterator.NewMergingIterator(children, keyComparator)
+	defer mergingIterator.Close()
+
+	var outputs []*SSTable
+	var output *SSTable
+	var writer *ssTableWriter
+	failed := func(err error) ([]*SSTable, error) {
+		if output != nil {
+			output.delete()
+		}
+		for _, written := range outputs {
+			written.delete()
+		}
+		return nil, err
+	}
+	finishOutput := func() error {
+		if err := writer.finish(); err != nil {
+			return err
+		}
+		outputs, output = append(outputs, output), nil
+		return nil
+	}
+	mayHoldAnOlderVersion := func(key model.Slice) bool {
+		return compaction.mayHoldAnOlderVersion(key, keyComparator)
+	}
+	now := time.Now()
+	for mergingIterator.SeekToFirst(); mergingIterator.Valid(); mergingIterator.Next() {
+		if err := ctx.Err(); err != nil {
+			return failed(err)
+		}
+		remainingEntries = remainingEntries - 1
+		keyValuePair := model.KeyValuePair{Key: mergingIterator.Key(), Value: mergingIterator.Value(), ExpiresAt: mergingIterator.ExpiresAt()}
+		if keyValuePair.IsExpiredAt(now) {
+			if !mayHoldAnOlderVersion(keyValuePair.Key) {
+				continue
+			}
+			keyValuePair = keyValuePair.AsTombstone()
+		}
+		keyValuePair, ok := filteredKeyValue(keyValuePair, ssTables.options.CompactionFilter, now, mayHoldAnOlderVersion)
+		if !ok {
+			continue
+		}
+		if output == nil {
+			if output, err = ssTables.newMerged(remainingEntries+1, compaction.inputs, keyComparator); err != nil {
+				return failed(err)
+			}
+			writer = newSSTableWriter(output)
+		}
+		if err := writer.add(keyValuePair); err != nil {
+			return failed(err)
+		}
+		if compaction.targetFileSize > 0 && writer.rawSize() >= compaction.targetFileSize {
+			if err := finishOutput(); err != nil {
+				return failed(err)
+			}
+		}
+	}
+	if output != nil {
+		if err := finishOutput(); err != nil {
+			return failed(err)
+		}
+	}
+	return outputs, nil
+}
+
+// totalEntries is the number of key/value pairs in the inputs, which bounds the number of keys in the merged ssTables.
+func totalEntries(inputs []*SSTable) (int, error) {
+	total := 0
+	for _, input := range inputs {
+		properties, err := input.Properties()
+		if err != nil {
+			return 0, err
+		}
+		total = total + int(properties.TotalEntries)
+	}
+	return total, nil
+}
+
+// newMerged creates an ssTable for at most capacity keys which takes the generation and the creation time of the newest input,
+// so that it is shadowed by the same ssTables that shadowed the inputs and it ages along with its newest data.
+func (ssTables *SSTables) newMerged(capacity int, inputs []*SSTable, keyComparator comparator.KeyComparator) (*SSTable, error) {
+	ssTables.lock.Lock()
+	fileId := ssTables.nextFileId
+	ssTables.nextFileId = ssTables.nextFileId + 1
+	ssTables.lock.Unlock()
+
+	output, err := newSSTableWithCapacity(capacity, ssTables.bloomFilters, ssTables.indexCache, ssTables.blockCache, ssTables.directory, fileId, ssTables.options)
+	if err != nil {
+		return nil, err
+	}
+	output.generation = 0
+	output.comparatorName = keyComparator.Name()
+	for _, input := range inputs {
+		if input.generation > output.generation {
+			output.generation = input.generation
+		}
+		if input.createdAt.After(output.createdAt) {
+			output.createdAt = input.createdAt
+		}
+		if output.smallestSequence == 0 || (input.smallestSequence != 0 && input.smallestSequence < output.smallestSequence) {
+			output.smallestSequence = input.smallestSequence
+		}
+		if input.largestSequence > output.largestSequence {
+			output.largestSequence = input.largestSequence
+		}
+	}
+	return output, nil
+}
+
+// replace publishes the outputs in place of the inputs and releases the reference SSTables held on the inputs.
+// Level 0 stays ordered by generation and every deeper level stays ordered by key.
+func (ssTables *SSTables) replace(compaction *compaction, outputs []*SSTable, keyComparator comparator.KeyComparator) {
+	for _, output := range outputs {
+		_, _ = output.index()
+		output.acquire()
+	}
+	isInput := make(map[*SSTable]bool)
+	for _, input := range compaction.inputs {
+		isInput[input] = true
+	}
+
+	ssTables.lock.Lock()
+	for len(ssTables.levels) <= compaction.outputLevel {
+		ssTables.levels = append(ssTables.levels, nil)
+	}
+	levels := make([][]*SSTable, len(ssTables.levels))
+	for level, tables := range ssTables.levels {
+		for _, table := range tables {
+			if !isInput[table] {
+				levels[level] = append(levels[level], table)
+			}
+		}
+	}
+	outputLevel := append(levels[compaction.outputLevel], outputs...)
+	if compaction.outputLevel == 0 {
+		sort.SliceStable(outputLevel, func(one, other int) bool {
+			return outputLevel[one].generation < outputLevel[other].generation
+		})
+	} else {
+		sort.SliceStable(outputLevel, func(one, other int) bool {
+			return keyComparator.Compare(outputLevel[one].smallestKey, outputLevel[other].smallestKey) < 0
+		})
+	}
+	levels[compaction.outputLevel] = outputLevel
+	ssTables.levels = levels
+	ssTables.lock.Unlock()
+
+	for _, input := range compaction.inputs {
+		input.release()
+	}
+}
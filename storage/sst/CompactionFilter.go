@@ -0,0 +1,66 @@
+package sst
+
+import (
+	"storage-engine-workshop/db/model"
+	"time"
+)
+
+// CompactionDecision is the decision of a CompactionFilter about a key/value pair.
+type CompactionDecision int
+
+const (
+	KeepValue CompactionDecision = iota
+	RemoveKey
+	ChangeValue
+)
+
+// CompactionFilter is consulted for every key/value pair written to an ssTable, while a memTable is flushed and while ssTables are merged.
+// It keeps the pair, removes the key or changes the value to the value it returns along with ChangeValue.
+// A removed key is written as a tombstone which shadows the older versions of the key in other ssTables,
+// the tombstone is dropped by a compaction once no ssTable outside the compaction may hold the key. A filter must be safe to call from concurrent flushes.
+type CompactionFilter interface {
+	Filter(key, value model.Slice) (CompactionDecision, model.Slice)
+}
+
+// CompactionFilterFunc adapts a function to a CompactionFilter.
+type CompactionFilterFunc func(key, value model.Slice) (CompactionDecision, model.Slice)
+
+func (filterFunc CompactionFilterFunc) Filter(key, value model.Slice) (CompactionDecision, model.Slice) {
+	return filterFunc(key, value)
+}
+
+// filteredKeyValues leaves the expired pairs, which are tombstones, out of the compaction filter.
+func filteredKeyValues(keyValuePairs []model.KeyValuePair, compactionFilter CompactionFilter, now time.Time, mayHoldAnOlderVersion func(key model.Slice) bool) []model.KeyValuePair {
+	if compactionFilter == nil {
+		return keyValuePairs
+	}
+	filtered := make([]model.KeyValuePair, 0, len(keyValuePairs))
+	for _, keyValuePair := range keyValuePairs {
+		if filteredKeyValuePair, ok := filteredKeyValue(keyValuePair, compactionFilter, now, mayHoldAnOlderVersion); ok {
+			filtered = append(filtered, filteredKeyValuePair)
+		}
+	}
+	return filtered
+}
+
+// filteredKeyValue answers false if the pair is dropped, a removed key is written as a tombstone if mayHoldAnOlderVersion answers true for the key.
+func filteredKeyValue(keyValuePair model.KeyValuePair, compactionFilter CompactionFilter, now time.Time, mayHoldAnOlderVersion func(key model.Slice) bool) (model.KeyValuePair, bool) {
+	if compactionFilter == nil || keyValuePair.IsExpiredAt(now) {
+		return keyValuePair, true
+	}
+	switch decision, value := compactionFilter.Filter(keyValuePair.Key, keyValuePair.Value); decision {
+	case RemoveKey:
+		if !mayHoldAnOlderVersion(keyValuePair.Key) {
+			return model.KeyValuePair{}, false
+		}
+		return model.KeyValuePair{Key: keyValuePair.Key, Value: model.NilSlice(), ExpiresAt: model.RemovedAt}, true
+	case ChangeValue:
+		keyValuePair.Value = value
+	}
+	return keyValuePair, true
+}
+
+// anyOlderVersion is used while a memTable is flushed, every ssTable is older than the memTable and may hold the key.
+func anyOlderVersion(key model.Slice) bool {
+	return true
+}
@@ -0,0 +1,69 @@
+package sst
+
+import (
+	"os"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"strings"
+	"testing"
+	"time"
+)
+
+func removingOrphansAndMaskingSecrets() CompactionFilter {
+	return CompactionFilterFunc(func(key, value model.Slice) (CompactionDecision, model.Slice) {
+		if strings.HasPrefix(key.AsString(), "Orphan") {
+			return RemoveKey, model.NilSlice()
+		}
+		if strings.HasPrefix(key.AsString(), "Secret") {
+			return ChangeValue, model.NewSlice([]byte("***"))
+		}
+		return KeepValue, value
+	})
+}
+
+func TestFiltersKeyValuesWhileFlushingAMemTable(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{CompactionFilter: removingOrphansAndMaskingSecrets()})
+	publishSSTableWith(ssTables, map[string]string{"Orphan-1": "Child", "Secret-1": "Password", "Company": "TW"})
+
+	if getResult := ssTables.Get(model.NewSlice([]byte("Orphan-1")), comparator.StringKeyComparator{}); !getResult.IsExpiredAt(time.Now()) {
+		t.Fatalf("Expected a tombstone for the key %v removed by the compaction filter", "Orphan-1")
+	}
+	if getResult := ssTables.Get(model.NewSlice([]byte("Secret-1")), comparator.StringKeyComparator{}); getResult.Value.AsString() != "***" {
+		t.Fatalf("Expected value to be %v, received %v", "***", getResult.Value.AsString())
+	}
+	if getResult := ssTables.Get(model.NewSlice([]byte("Company")), comparator.StringKeyComparator{}); getResult.Value.AsString() != "TW" {
+		t.Fatalf("Expected value to be %v, received %v", "TW", getResult.Value.AsString())
+	}
+}
+
+func TestFiltersTheNewestVersionOfKeyValuesWhileCompacting(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	publishSSTableWith(ssTables, map[string]string{"Orphan-1": "Child", "Secret-1": "Password-1"})
+	publishSSTableWith(ssTables, map[string]string{"Secret-1": "Password-2"})
+	publishSSTableWith(ssTables, map[string]string{"Company": "TW"})
+	publishSSTableWith(ssTables, map[string]string{"Orphan-2": "Child"})
+
+	var filtered []string
+	filter := removingOrphansAndMaskingSecrets()
+	ssTables.options.CompactionFilter = CompactionFilterFunc(func(key, value model.Slice) (CompactionDecision, model.Slice) {
+		filtered = append(filtered, key.AsString()+"="+value.AsString())
+		return filter.Filter(key, value)
+	})
+	_, _ = ssTables.Compact(comparator.StringKeyComparator{})
+
+	if len(filtered) != 4 || filtered[3] != "Secret-1=Password-2" {
+		t.Fatalf("Expected the filter to see the newest version of %v keys, received %v", 4, filtered)
+	}
+	if properties, _ := ssTables.levels[0][0].Properties(); properties.TotalEntries != 2 {
+		t.Fatalf("Expected %v entries in the compacted ssTable, received %v", 2, properties.TotalEntries)
+	}
+	if getResult := ssTables.Get(model.NewSlice([]byte("Secret-1")), comparator.StringKeyComparator{}); getResult.Value.AsString() != "***" {
+		t.Fatalf("Expected value to be %v, received %v", "***", getResult.Value.AsString())
+	}
+}
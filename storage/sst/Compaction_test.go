@@ -0,0 +1,302 @@
+package sst
+
+import (
+	"os"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"storage-engine-workshop/storage/memory"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func publishSSTableWith(ssTables *SSTables, keyValuePairs map[string]string) *SSTable {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	for key, value := range keyValuePairs {
+		memTable.Put(model.NewSlice([]byte(key)), model.NewSlice([]byte(value)))
+	}
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+	ssTables.AllowSearchIn(ssTable)
+	return ssTable
+}
+
+func fileExists(filePath string) bool {
+	_, err := os.Stat(filePath)
+	return err == nil
+}
+
+func TestCompactsSimilarlySizedSSTablesKeepingTheNewestVersionOfEveryKey(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	var inputs []*SSTable
+	for table := 1; table <= 4; table++ {
+		inputs = append(inputs, publishSSTableWith(ssTables, map[string]string{
+			"Company":                    "TW-" + strconv.Itoa(table),
+			"Key-" + strconv.Itoa(table): "Value-" + strconv.Itoa(table),
+		}))
+	}
+
+	compacted, err := ssTables.Compact(comparator.StringKeyComparator{})
+	if !compacted || err != nil {
+		t.Fatalf("Expected the ssTables to be compacted, received %v with error %v", compacted, err)
+	}
+	if len(ssTables.levels[0]) != 1 {
+		t.Fatalf("Expected a single ssTable after compaction, received %v", len(ssTables.levels[0]))
+	}
+	if getResult := ssTables.Get(model.NewSlice([]byte("Company")), comparator.StringKeyComparator{}); getResult.Value.AsString() != "TW-4" {
+		t.Fatalf("Expected value to be %v, received %v", "TW-4", getResult.Value.AsString())
+	}
+	for table := 1; table <= 4; table++ {
+		key := "Key-" + strconv.Itoa(table)
+		if !ssTables.levels[0][0].bloomFilter.Has(model.NewSlice([]byte(key))) {
+			t.Fatalf("Expected the bloom filter of the compacted ssTable to have %v", key)
+		}
+		if getResult := ssTables.Get(model.NewSlice([]byte(key)), comparator.StringKeyComparator{}); getResult.Value.AsString() != "Value-"+strconv.Itoa(table) {
+			t.Fatalf("Expected value to be %v, received %v", "Value-"+strconv.Itoa(table), getResult.Value.AsString())
+		}
+	}
+	if properties, _ := ssTables.levels[0][0].Properties(); properties.TotalEntries != 5 {
+		t.Fatalf("Expected %v entries in the compacted ssTable, received %v", 5, properties.TotalEntries)
+	}
+	for _, input := range inputs {
+		if fileExists(input.store.file.Name()) || fileExists(input.bloomFilter.FileName()) {
+			t.Fatalf("Expected the files of the compacted ssTable %v to be deleted", input.fileId)
+		}
+	}
+}
+
+func TestDeletesCompactedSSTablesOnlyAfterTheSnapshotReferencingThemIsReleased(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	var inputs []*SSTable
+	for table := 1; table <= 4; table++ {
+		inputs = append(inputs, publishSSTableWith(ssTables, map[string]string{"Key-" + strconv.Itoa(table): "Value-" + strconv.Itoa(table)}))
+	}
+	snapshot := ssTables.Snapshot()
+	_, _ = ssTables.Compact(comparator.StringKeyComparator{})
+
+	if getResult := snapshot.Get(model.NewSlice([]byte("Key-1")), comparator.StringKeyComparator{}); getResult.Value.AsString() != "Value-1" {
+		t.Fatalf("Expected value to be %v, received %v", "Value-1", getResult.Value.AsString())
+	}
+	if !fileExists(inputs[0].store.file.Name()) {
+		t.Fatalf("Expected the file of the compacted ssTable to exist while a snapshot references it")
+	}
+	snapshot.Release()
+	if fileExists(inputs[0].store.file.Name()) {
+		t.Fatalf("Expected the file of the compacted ssTable to be deleted once the snapshot is released")
+	}
+}
+
+func TestCompactsARunOfSSTablesInPlaceOfTheRunSoThatNewerSSTablesStillShadowIt(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	for table := 1; table <= 4; table++ {
+		publishSSTableWith(ssTables, map[string]string{"Company": "TW-" + strconv.Itoa(table)})
+	}
+	newest := make(map[string]string)
+	for count := 0; count < 100; count++ {
+		newest["Key-"+strconv.Itoa(count)] = "Value-" + strconv.Itoa(count)
+	}
+	newest["Company"] = "TW-5"
+	publishSSTableWith(ssTables, newest)
+
+	if compacted, _ := ssTables.Compact(comparator.StringKeyComparator{}); !compacted {
+		t.Fatalf("Expected the run of similarly sized ssTables to be compacted")
+	}
+	if len(ssTables.levels[0]) != 2 {
+		t.Fatalf("Expected %v ssTables after compaction, received %v", 2, len(ssTables.levels[0]))
+	}
+	if getResult := ssTables.Get(model.NewSlice([]byte("Company")), comparator.StringKeyComparator{}); getResult.Value.AsString() != "TW-5" {
+		t.Fatalf("Expected value to be %v, received %v", "TW-5", getResult.Value.AsString())
+	}
+}
+
+func TestDoesNotCompactFewerSSTablesThanTheMinMergeWidth(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{Compaction: SizeTieredCompactionOptions{MinMergeWidth: 4}})
+	for table := 1; table <= 3; table++ {
+		publishSSTableWith(ssTables, map[string]string{"Key-" + strconv.Itoa(table): "Value-" + strconv.Itoa(table)})
+	}
+	if compacted, _ := ssTables.Compact(comparator.StringKeyComparator{}); compacted {
+		t.Fatalf("Expected %v ssTables not to be compacted", 3)
+	}
+}
+
+func TestCountsTheSSTablesOfEveryMergeableRunAsTheCompactionBacklog(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{Compaction: SizeTieredCompactionOptions{MinMergeWidth: 2, MaxMergeWidth: 2}})
+	publishSSTableWith(ssTables, map[string]string{"Key-1": "Value-1"})
+	if backlog := ssTables.CompactionBacklog(); backlog != 0 {
+		t.Fatalf("Expected a backlog of %v ssTables below the min merge width, received %v", 0, backlog)
+	}
+	for table := 2; table <= 5; table++ {
+		publishSSTableWith(ssTables, map[string]string{"Key-" + strconv.Itoa(table): "Value-" + strconv.Itoa(table)})
+	}
+	if backlog := ssTables.CompactionBacklog(); backlog != 4 {
+		t.Fatalf("Expected a backlog of the %v ssTables in the two runs of the max merge width, received %v", 4, backlog)
+	}
+}
+
+func TestDropsExpiredKeyValuesWhileCompacting(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTable.PutWithExpiry(model.NewSlice([]byte("Session")), model.NewSlice([]byte("Token")), model.ExpiresAfter(50*time.Millisecond))
+	memTable.Put(model.NewSlice([]byte("Key-0")), model.NewSlice([]byte("Value-0")))
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+	ssTables.AllowSearchIn(ssTable)
+	for table := 1; table <= 3; table++ {
+		publishSSTableWith(ssTables, map[string]string{"Key-" + strconv.Itoa(table): "Value-" + strconv.Itoa(table)})
+	}
+	time.Sleep(60 * time.Millisecond)
+
+	_, _ = ssTables.Compact(comparator.StringKeyComparator{})
+	if properties, _ := ssTables.levels[0][0].Properties(); properties.TotalEntries != 4 {
+		t.Fatalf("Expected %v unexpired entries in the compacted ssTable, received %v", 4, properties.TotalEntries)
+	}
+}
+
+func TestKeepsTheTombstoneOfAnExpiredKeyValueWhileAnOlderSSTableOutsideTheCompactionHoldsTheKey(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	olderKeyValues := map[string]string{"Session": "Old-Token"}
+	for count := 1; count <= 100; count++ {
+		olderKeyValues["Older-Key-"+strconv.Itoa(count)] = "Older-Value-" + strconv.Itoa(count)
+	}
+	older := publishSSTableWith(ssTables, olderKeyValues)
+
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTable.PutWithExpiry(model.NewSlice([]byte("Session")), model.NewSlice([]byte("Token")), model.ExpiresAfter(50*time.Millisecond))
+	memTable.Put(model.NewSlice([]byte("Key-0")), model.NewSlice([]byte("Value-0")))
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+	ssTables.AllowSearchIn(ssTable)
+	for table := 1; table <= 3; table++ {
+		publishSSTableWith(ssTables, map[string]string{"Key-" + strconv.Itoa(table): "Value-" + strconv.Itoa(table)})
+	}
+	time.Sleep(60 * time.Millisecond)
+
+	if compacted, err := ssTables.Compact(comparator.StringKeyComparator{}); !compacted || err != nil {
+		t.Fatalf("Expected the ssTables to be compacted, received %v with error %v", compacted, err)
+	}
+	if len(ssTables.levels[0]) != 2 || ssTables.levels[0][0] != older {
+		t.Fatalf("Expected the older ssTable to stay out of the compaction, received %v ssTables", len(ssTables.levels[0]))
+	}
+	if properties, _ := ssTables.levels[0][1].Properties(); properties.TotalEntries != 5 {
+		t.Fatalf("Expected %v entries including the tombstone in the compacted ssTable, received %v", 5, properties.TotalEntries)
+	}
+	if getResult := ssTables.Get(model.NewSlice([]byte("Session")), comparator.StringKeyComparator{}); !getResult.IsExpiredAt(time.Now()) {
+		t.Fatalf("Expected the tombstone to shadow the older version %v, received %v", "Old-Token", getResult.Value.AsString())
+	}
+}
+
+func TestDropsTheTombstoneOfAnExpiredKeyValueWhichNoOlderSSTableOutsideTheCompactionHolds(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	olderKeyValues := map[string]string{"A-Key": "A-Value", "Z-Key": "Z-Value"}
+	for count := 1; count <= 100; count++ {
+		olderKeyValues["Older-Key-"+strconv.Itoa(count)] = "Older-Value-" + strconv.Itoa(count)
+	}
+	publishSSTableWith(ssTables, olderKeyValues)
+
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTable.PutWithExpiry(model.NewSlice([]byte("Session")), model.NewSlice([]byte("Token")), model.ExpiresAfter(50*time.Millisecond))
+	memTable.Put(model.NewSlice([]byte("Key-0")), model.NewSlice([]byte("Value-0")))
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+	ssTables.AllowSearchIn(ssTable)
+	for table := 1; table <= 3; table++ {
+		publishSSTableWith(ssTables, map[string]string{"Key-" + strconv.Itoa(table): "Value-" + strconv.Itoa(table)})
+	}
+	time.Sleep(60 * time.Millisecond)
+
+	if compacted, err := ssTables.Compact(comparator.StringKeyComparator{}); !compacted || err != nil {
+		t.Fatalf("Expected the ssTables to be compacted, received %v with error %v", compacted, err)
+	}
+	if properties, _ := ssTables.levels[0][1].Properties(); properties.TotalEntries != 4 {
+		t.Fatalf("Expected %v entries without the tombstone in the compacted ssTable, received %v", 4, properties.TotalEntries)
+	}
+}
+
+func TestDeletesCompactedSSTablesWhileConcurrentReadsRun(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	var inputs []*SSTable
+	for table := 1; table <= 4; table++ {
+		inputs = append(inputs, publishSSTableWith(ssTables, map[string]string{"Key-" + strconv.Itoa(table): "Value-" + strconv.Itoa(table)}))
+	}
+
+	stop := make(chan struct{})
+	failures := make(chan string, 8)
+	var readers sync.WaitGroup
+	for reader := 0; reader < 4; reader++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if getResult := ssTables.Get(model.NewSlice([]byte("Key-1")), comparator.StringKeyComparator{}); getResult.Value.AsString() != "Value-1" {
+					failures <- "Expected value to be Value-1, received " + getResult.Value.AsString()
+					return
+				}
+				keys := 0
+				for _, ssTableIterator := range ssTables.NewIterators(comparator.StringKeyComparator{}, ReadOptions{}) {
+					for ssTableIterator.SeekToFirst(); ssTableIterator.Valid(); ssTableIterator.Next() {
+						keys++
+					}
+					ssTableIterator.Close()
+				}
+				if keys != 4 {
+					failures <- "Expected 4 keys across the iterators, received " + strconv.Itoa(keys)
+					return
+				}
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	if compacted, err := ssTables.Compact(comparator.StringKeyComparator{}); !compacted || err != nil {
+		t.Fatalf("Expected the ssTables to be compacted, received %v with error %v", compacted, err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(stop)
+	readers.Wait()
+
+	select {
+	case failure := <-failures:
+		t.Fatal(failure)
+	default:
+	}
+	for _, input := range inputs {
+		if input.referenceCount() != 0 {
+			t.Fatalf("Expected no reference on the compacted ssTable %v, received %v", input.fileId, input.referenceCount())
+		}
+		if fileExists(input.store.file.Name()) || fileExists(input.bloomFilter.FileName()) {
+			t.Fatalf("Expected the files of the compacted ssTable %v to be deleted once the reads released it", input.fileId)
+		}
+	}
+}
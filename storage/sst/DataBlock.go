@@ -0,0 +1,185 @@
+package sst
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"storage-engine-workshop/storage/compression"
+	"unsafe"
+)
+
+var reservedRestartOffsetSize = unsafe.Sizeof(uint32(0))
+
+// DataBlock holds the key/value pairs of a block in key order, every pair is encoded as a PersistentSSTableSlice
+// which leaves out the key prefix it shares with the previous key. Every restartInterval-th pair is a restart point
+// which stores its key in full, a lookup binary searches the restart points and decodes only the pairs after one of them.
+type DataBlock struct {
+	contents       []byte
+	restartOffsets []int
+	size           int
+}
+
+func NewDataBlock(contents []byte) (*DataBlock, error) {
+	if len(contents) < int(reservedRestartOffsetSize) {
+		return nil, errors.New(fmt.Sprintf("data block of size %v is corrupt", len(contents)))
+	}
+	totalRestarts := int(bigEndian.Uint32(contents[len(contents)-int(reservedRestartOffsetSize):]))
+	restartOffsetsOffset := len(contents) - int(reservedRestartOffsetSize)*(totalRestarts+1)
+	if restartOffsetsOffset < 0 {
+		return nil, errors.New(fmt.Sprintf("data block of size %v claims %v restart points which do not fit in its size", len(contents), totalRestarts))
+	}
+	restartOffsets := make([]int, totalRestarts)
+	for index := 0; index < totalRestarts; index++ {
+		restartOffsets[index] = int(bigEndian.Uint32(contents[restartOffsetsOffset+index*int(reservedRestartOffsetSize):]))
+	}
+	return &DataBlock{contents: contents[:restartOffsetsOffset], restartOffsets: restartOffsets, size: len(contents)}, nil
+}
+
+// ReadDataBlock decompresses the block with the codec whose id is the last byte of the block.
+func ReadDataBlock(store *Store, blockHandle BlockHandle) (*DataBlock, error) {
+	if blockHandle.Size < 1 {
+		return nil, errors.New(fmt.Sprintf("data block of size %v in ssTable file %v is corrupt", blockHandle.Size, store.file.Name()))
+	}
+	contents := make([]byte, blockHandle.Size)
+	if _, err := store.ReadAt(contents, blockHandle.Offset); err != nil {
+		return nil, err
+	}
+	codec, err := compression.CodecFor(contents[len(contents)-1])
+	if err != nil {
+		return nil, err
+	}
+	decompressed, err := codec.Decompress(contents[:len(contents)-1])
+	if err != nil {
+		return nil, err
+	}
+	return NewDataBlock(decompressed)
+}
+
+// compressDataBlock falls back to no compression if the codec does not shrink the block.
+func compressDataBlock(contents []byte, codec compression.Codec) ([]byte, error) {
+	//The way compressed data block is encoded is: Compressed contents | 1 byte for codec id
+	compressed, err := codec.Compress(contents)
+	if err != nil {
+		return nil, err
+	}
+	if len(compressed) >= len(contents) {
+		compressed, codec = contents, compression.NoCodec
+	}
+	return append(compressed, codec.Id()), nil
+}
+
+func (dataBlock *DataBlock) Get(key model.Slice, keyComparator comparator.KeyComparator) (model.KeyValuePair, bool) {
+	if len(dataBlock.restartOffsets) == 0 {
+		return model.KeyValuePair{}, false
+	}
+	restartIndex := sort.Search(len(dataBlock.restartOffsets), func(index int) bool {
+		restartKey, _ := dataBlock.decodeAt(dataBlock.restartOffsets[index], nil)
+		return keyComparator.Compare(restartKey.Key, key) > 0
+	}) - 1
+	if restartIndex < 0 {
+		restartIndex = 0
+	}
+	offset, previousKey := dataBlock.restartOffsets[restartIndex], []byte(nil)
+	for offset < len(dataBlock.contents) {
+		keyValuePair, nextOffset := dataBlock.decodeAt(offset, previousKey)
+		comparison := keyComparator.Compare(keyValuePair.Key, key)
+		if comparison == 0 {
+			return keyValuePair, true
+		}
+		if comparison > 0 {
+			break
+		}
+		offset, previousKey = nextOffset, keyValuePair.Key.GetRawContent()
+	}
+	return model.KeyValuePair{}, false
+}
+
+// Size is the size of the decompressed block, which is what the block occupies in the block cache.
+func (dataBlock *DataBlock) Size() int64 {
+	return int64(dataBlock.size)
+}
+
+func (dataBlock *DataBlock) KeyValuePairs() []model.KeyValuePair {
+	var keyValuePairs []model.KeyValuePair
+	offset, previousKey := 0, []byte(nil)
+	for offset < len(dataBlock.contents) {
+		keyValuePair, nextOffset := dataBlock.decodeAt(offset, previousKey)
+		keyValuePairs = append(keyValuePairs, keyValuePair)
+		offset, previousKey = nextOffset, keyValuePair.Key.GetRawContent()
+	}
+	return keyValuePairs
+}
+
+// decodeAt decodes the pair at the offset, previousKey supplies the shared prefix of the key and is ignored at a restart point.
+func (dataBlock *DataBlock) decodeAt(offset int, previousKey []byte) (model.KeyValuePair, int) {
+	sharedKeySize, unsharedKey, value, expiresAt, totalSize := NewPersistentSSTableSliceKeyValuePair(dataBlock.contents[offset:])
+
+	key := make([]byte, 0, sharedKeySize+unsharedKey.Size())
+	key = append(key, previousKey[:sharedKeySize]...)
+	key = append(key, unsharedKey.GetPersistentContents()...)
+	return model.KeyValuePair{Key: model.NewSlice(key), Value: value.GetSlice(), ExpiresAt: expiresAt}, offset + totalSize
+}
+
+// DataBlockBuilder accumulates key/value pairs, added in key order, till the ssTable decides to finish the block.
+type DataBlockBuilder struct {
+	contents          []byte
+	restartOffsets    []uint32
+	restartInterval   int
+	pairsSinceRestart int
+	lastKey           model.Slice
+}
+
+func NewDataBlockBuilder(restartInterval int) *DataBlockBuilder {
+	return &DataBlockBuilder{restartInterval: restartInterval}
+}
+
+func (dataBlockBuilder *DataBlockBuilder) Add(keyValuePair model.KeyValuePair) {
+	sharedKeySize := 0
+	if len(dataBlockBuilder.restartOffsets) == 0 || dataBlockBuilder.pairsSinceRestart >= dataBlockBuilder.restartInterval {
+		dataBlockBuilder.restartOffsets = append(dataBlockBuilder.restartOffsets, uint32(len(dataBlockBuilder.contents)))
+		dataBlockBuilder.pairsSinceRestart = 0
+	} else {
+		sharedKeySize = sharedPrefixSize(dataBlockBuilder.lastKey.GetRawContent(), keyValuePair.Key.GetRawContent())
+	}
+	dataBlockBuilder.contents = append(dataBlockBuilder.contents, NewPersistentSSTableSlice(keyValuePair, sharedKeySize).GetPersistentContents()...)
+	dataBlockBuilder.pairsSinceRestart = dataBlockBuilder.pairsSinceRestart + 1
+	dataBlockBuilder.lastKey = keyValuePair.Key
+}
+
+// Size estimates the size of the finished block, including its restart points.
+func (dataBlockBuilder *DataBlockBuilder) Size() int {
+	return len(dataBlockBuilder.contents) + int(reservedRestartOffsetSize)*(len(dataBlockBuilder.restartOffsets)+1)
+}
+
+func (dataBlockBuilder *DataBlockBuilder) IsEmpty() bool {
+	return len(dataBlockBuilder.contents) == 0
+}
+
+func (dataBlockBuilder *DataBlockBuilder) LastKey() model.Slice {
+	return dataBlockBuilder.lastKey
+}
+
+func (dataBlockBuilder *DataBlockBuilder) Finish() []byte {
+	//The way data block is encoded is: Entry 0 | ... | Entry N | 4 bytes offset of every restart point | 4 bytes for totalRestarts
+	restartOffsets := make([]byte, int(reservedRestartOffsetSize)*(len(dataBlockBuilder.restartOffsets)+1))
+	for index, restartOffset := range dataBlockBuilder.restartOffsets {
+		bigEndian.PutUint32(restartOffsets[index*int(reservedRestartOffsetSize):], restartOffset)
+	}
+	bigEndian.PutUint32(restartOffsets[len(dataBlockBuilder.restartOffsets)*int(reservedRestartOffsetSize):], uint32(len(dataBlockBuilder.restartOffsets)))
+	return append(dataBlockBuilder.contents, restartOffsets...)
+}
+
+func (dataBlockBuilder *DataBlockBuilder) Reset() {
+	dataBlockBuilder.contents, dataBlockBuilder.restartOffsets, dataBlockBuilder.lastKey = nil, nil, model.NilSlice()
+	dataBlockBuilder.pairsSinceRestart = 0
+}
+
+func sharedPrefixSize(previousKey, key []byte) int {
+	size := 0
+	for size < len(previousKey) && size < len(key) && previousKey[size] == key[size] {
+		size = size + 1
+	}
+	return size
+}
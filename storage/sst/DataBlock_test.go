@@ -0,0 +1,86 @@
+package sst
+
+import (
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"strconv"
+	"testing"
+)
+
+func keyValuePairsWithACommonPrefix(totalPairs int) []model.KeyValuePair {
+	var keyValuePairs []model.KeyValuePair
+	for count := 10; count < 10+totalPairs; count++ {
+		keyValuePairs = append(keyValuePairs, model.KeyValuePair{
+			Key:   model.NewSlice([]byte("tenant-storage-engine:user:" + strconv.Itoa(count))),
+			Value: model.NewSlice([]byte("Value-" + strconv.Itoa(count))),
+		})
+	}
+	return keyValuePairs
+}
+
+func TestGetsEveryKeyOfAPrefixCompressedDataBlock(t *testing.T) {
+	keyValuePairs := keyValuePairsWithACommonPrefix(30)
+	dataBlockBuilder := NewDataBlockBuilder(4)
+	for _, keyValuePair := range keyValuePairs {
+		dataBlockBuilder.Add(keyValuePair)
+	}
+	dataBlock, err := NewDataBlock(dataBlockBuilder.Finish())
+	if err != nil {
+		t.Fatalf("Expected no error while decoding the data block, received %v", err)
+	}
+	if len(dataBlock.restartOffsets) != 8 {
+		t.Fatalf("Expected %v restart points, received %v", 8, len(dataBlock.restartOffsets))
+	}
+	for _, keyValuePair := range keyValuePairs {
+		found, ok := dataBlock.Get(keyValuePair.Key, comparator.StringKeyComparator{})
+		if !ok || found.Value.AsString() != keyValuePair.Value.AsString() {
+			t.Fatalf("Expected value to be %v, received %v", keyValuePair.Value.AsString(), found.Value.AsString())
+		}
+	}
+	for _, missingKey := range []string{"a", "tenant-storage-engine:user:100", "z"} {
+		if _, ok := dataBlock.Get(model.NewSlice([]byte(missingKey)), comparator.StringKeyComparator{}); ok {
+			t.Fatalf("Expected key %v to be missing, but was present", missingKey)
+		}
+	}
+	decodedPairs := dataBlock.KeyValuePairs()
+	for index, keyValuePair := range keyValuePairs {
+		if decodedPairs[index].Key.AsString() != keyValuePair.Key.AsString() {
+			t.Fatalf("Expected key to be %v, received %v", keyValuePair.Key.AsString(), decodedPairs[index].Key.AsString())
+		}
+	}
+}
+
+// fullKeyEntrySize is the size of a key/value pair as it was encoded before prefix compression:
+// 4 bytes for totalSize | 4 bytes for keySize | 8 bytes for expiresAt | Key content | Value content
+func fullKeyEntrySize(keyValuePair model.KeyValuePair) int {
+	return 16 + keyValuePair.Key.Size() + keyValuePair.Value.Size()
+}
+
+func TestPrefixCompressedDataBlockIsSmallerThanADataBlockOfFullKeyEntries(t *testing.T) {
+	dataBlockBuilder, fullKeyEntriesSize := NewDataBlockBuilder(16), 0
+	for _, keyValuePair := range keyValuePairsWithACommonPrefix(30) {
+		dataBlockBuilder.Add(keyValuePair)
+		fullKeyEntriesSize = fullKeyEntriesSize + fullKeyEntrySize(keyValuePair)
+	}
+	fullKeyBlockSize := fullKeyEntriesSize + int(reservedRestartOffsetSize)
+	if compressedSize := len(dataBlockBuilder.Finish()); compressedSize >= fullKeyBlockSize {
+		t.Fatalf("Expected the compressed block to be smaller than %v bytes, received %v bytes", fullKeyBlockSize, compressedSize)
+	}
+}
+
+func TestEncodesAKeyValuePairWithoutASharedPrefixInFewerBytesThanAFullKeyEntry(t *testing.T) {
+	for _, keyValuePair := range []model.KeyValuePair{
+		{Key: model.NewSlice([]byte("Company")), Value: model.NewSlice([]byte("TW"))},
+		{Key: model.NewSlice([]byte("K")), Value: model.NilSlice()},
+		{Key: model.NewSlice([]byte("tenant-storage-engine:user:10")), Value: model.NewSlice(make([]byte, 300)), ExpiresAt: 1700000000},
+	} {
+		encoded := NewPersistentSSTableSlice(keyValuePair, 0)
+		if encoded.Size() >= fullKeyEntrySize(keyValuePair) {
+			t.Fatalf("Expected the encoding of key %v to be smaller than %v bytes, received %v bytes", keyValuePair.Key.AsString(), fullKeyEntrySize(keyValuePair), encoded.Size())
+		}
+		sharedKeySize, key, value, expiresAt, size := NewPersistentSSTableSliceKeyValuePair(encoded.GetPersistentContents())
+		if sharedKeySize != 0 || key.GetSlice().AsString() != keyValuePair.Key.AsString() || value.Size() != keyValuePair.Value.Size() || expiresAt != keyValuePair.ExpiresAt || size != encoded.Size() {
+			t.Fatalf("Expected key %v to be decoded as it was encoded, received key %v of %v bytes", keyValuePair.Key.AsString(), key.GetSlice().AsString(), size)
+		}
+	}
+}
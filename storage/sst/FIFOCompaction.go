@@ -0,0 +1,60 @@
+package sst
+
+import (
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"time"
+)
+
+// FIFOCompactionOptions configure FIFO compaction which never merges ssTables on its own, it drops whole ssTables from the oldest one
+// once they are older than MaxAge or once the total size of the ssTables exceeds MaxTotalSize. A zero value disables that limit.
+// FIFO compaction suits data that is written once and read only till it ages out, the dropped keys are not visible any more.
+type FIFOCompactionOptions struct {
+	MaxAge       time.Duration
+	MaxTotalSize int64
+}
+
+func (options FIFOCompactionOptions) pick(levels [][]*SSTable, keyComparator comparator.KeyComparator) *compaction {
+	return options.pickAt(levels, time.Now())
+}
+
+func (options FIFOCompactionOptions) pickAt(levels [][]*SSTable, now time.Time) *compaction {
+	var totalSize int64
+	for _, table := range levels[0] {
+		totalSize = totalSize + table.Size()
+	}
+	var inputs []*SSTable
+	for _, table := range levels[0] {
+		isTooOld := options.MaxAge > 0 && now.Sub(table.createdAt) > options.MaxAge
+		isOverSize := options.MaxTotalSize > 0 && totalSize > options.MaxTotalSize
+		if !isTooOld && !isOverSize {
+			break
+		}
+		inputs = append(inputs, table)
+		totalSize = totalSize - table.Size()
+	}
+	if len(inputs) == 0 {
+		return nil
+	}
+	return &compaction{inputs: inputs, outputLevel: 0, dropInputs: true}
+}
+
+// backlog is the number of ssTables which are due to be dropped.
+func (options FIFOCompactionOptions) backlog(levels [][]*SSTable) int {
+	if compaction := options.pickAt(levels, time.Now()); compaction != nil {
+		return len(compaction.inputs)
+	}
+	return 0
+}
+
+// nextDueAt answers the time the oldest ssTable grows older than MaxAge, which drops it even if no flush queues a compaction.
+func (options FIFOCompactionOptions) nextDueAt(levels [][]*SSTable) (time.Time, bool) {
+	if options.MaxAge <= 0 || len(levels[0]) == 0 {
+		return time.Time{}, false
+	}
+	return levels[0][0].createdAt.Add(options.MaxAge), true
+}
+
+func (options FIFOCompactionOptions) pickRange(levels [][]*SSTable, level int, lowerBound, upperBound model.Slice, keyComparator comparator.KeyComparator) *compaction {
+	return level0RunOverlapping(levels, level, lowerBound, upperBound, keyComparator)
+}
@@ -0,0 +1,77 @@
+package sst
+
+import (
+	"os"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDropsTheOldestSSTablesOnceTheTotalSizeExceedsTheMaximum(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	var tables []*SSTable
+	for table := 1; table <= 4; table++ {
+		tables = append(tables, publishSSTableWith(ssTables, map[string]string{"Key-" + strconv.Itoa(table): "Value-" + strconv.Itoa(table)}))
+	}
+	ssTables.options.Compaction = FIFOCompactionOptions{MaxTotalSize: 2 * tables[0].Size()}
+
+	if compacted, err := ssTables.Compact(comparator.StringKeyComparator{}); !compacted || err != nil {
+		t.Fatalf("Expected the oldest ssTables to be dropped, received %v with error %v", compacted, err)
+	}
+	if len(ssTables.levels[0]) != 2 {
+		t.Fatalf("Expected %v ssTables after dropping the oldest, received %v", 2, len(ssTables.levels[0]))
+	}
+	if getResult := ssTables.Get(model.NewSlice([]byte("Key-1")), comparator.StringKeyComparator{}); getResult.Exists {
+		t.Fatalf("Expected key %v of a dropped ssTable to be missing", "Key-1")
+	}
+	if getResult := ssTables.Get(model.NewSlice([]byte("Key-4")), comparator.StringKeyComparator{}); getResult.Value.AsString() != "Value-4" {
+		t.Fatalf("Expected value to be %v, received %v", "Value-4", getResult.Value.AsString())
+	}
+	if fileExists(tables[0].store.file.Name()) || fileExists(tables[1].bloomFilter.FileName()) {
+		t.Fatalf("Expected the files of the dropped ssTables to be deleted")
+	}
+}
+
+func TestPicksSSTablesOlderThanTheMaximumAgeToDrop(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	now := time.Now()
+	for table := 1; table <= 3; table++ {
+		publishSSTableWith(ssTables, map[string]string{"Key-" + strconv.Itoa(table): "Value-" + strconv.Itoa(table)}).createdAt = now.Add(time.Duration(table-4) * time.Hour)
+	}
+
+	compaction := FIFOCompactionOptions{MaxAge: 90 * time.Minute}.pickAt(ssTables.levels, now)
+	if compaction == nil || len(compaction.inputs) != 2 || !compaction.dropInputs {
+		t.Fatalf("Expected the %v ssTables older than the maximum age to be dropped, received %v", 2, compaction)
+	}
+	if compaction := (FIFOCompactionOptions{}).pickAt(ssTables.levels, now); compaction != nil {
+		t.Fatalf("Expected no ssTables to be dropped without limits, received %v", compaction.inputs)
+	}
+}
+
+func TestAnswersTheTimeTheOldestSSTableGrowsOlderThanTheMaximumAge(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{Compaction: FIFOCompactionOptions{MaxAge: time.Minute}})
+	if _, ok := ssTables.NextCompactionDueAt(); ok {
+		t.Fatalf("Expected no compaction to fall due without ssTables")
+	}
+	oldest := publishSSTableWith(ssTables, map[string]string{"Key-1": "Value-1"})
+	publishSSTableWith(ssTables, map[string]string{"Key-2": "Value-2"})
+
+	if dueAt, ok := ssTables.NextCompactionDueAt(); !ok || !dueAt.Equal(oldest.CreatedAt().Add(time.Minute)) {
+		t.Fatalf("Expected a compaction to fall due at %v, received %v", oldest.CreatedAt().Add(time.Minute), dueAt)
+	}
+	ssTables.options.Compaction = SizeTieredCompactionOptions{}
+	if _, ok := ssTables.NextCompactionDueAt(); ok {
+		t.Fatalf("Expected no compaction to fall due as ssTables age with size-tiered compaction")
+	}
+}
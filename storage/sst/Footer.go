@@ -0,0 +1,64 @@
+package sst
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+const (
+	footerMagicNumber    uint64 = 0x7373742d776f726b
+	currentFormatVersion uint32 = 1
+)
+
+var (
+	reservedFormatVersionSize = unsafe.Sizeof(uint32(0))
+	reservedMagicNumberSize   = unsafe.Sizeof(uint64(0))
+	footerSize                = int64(2*reservedBlockHandleSize + reservedFormatVersionSize + reservedMagicNumberSize)
+)
+
+// Footer is the fixed size tail of an ssTable file which locates the index and the properties blocks.
+// The magic number tells an ssTable apart from a truncated or a foreign file.
+type Footer struct {
+	IndexHandle      BlockHandle
+	PropertiesHandle BlockHandle
+	FormatVersion    uint32
+}
+
+func ReadFooter(store *Store) (Footer, error) {
+	size, err := store.Size()
+	if err != nil {
+		return Footer{}, err
+	}
+	if size < footerSize {
+		return Footer{}, errors.New(fmt.Sprintf("ssTable file %v of size %v is smaller than the footer", store.file.Name(), size))
+	}
+	bytes := make([]byte, footerSize)
+	if _, err := store.ReadAt(bytes, size-footerSize); err != nil {
+		return Footer{}, err
+	}
+	if magicNumber := bigEndian.Uint64(bytes[footerSize-int64(reservedMagicNumberSize):]); magicNumber != footerMagicNumber {
+		return Footer{}, errors.New(fmt.Sprintf("file %v is not an ssTable, found magic number %x", store.file.Name(), magicNumber))
+	}
+	footer := Footer{
+		IndexHandle:      decodeBlockHandle(bytes),
+		PropertiesHandle: decodeBlockHandle(bytes[reservedBlockHandleSize:]),
+		FormatVersion:    bigEndian.Uint32(bytes[2*reservedBlockHandleSize:]),
+	}
+	if footer.FormatVersion != currentFormatVersion {
+		return Footer{}, errors.New(fmt.Sprintf("ssTable file %v has an unsupported format version %v", store.file.Name(), footer.FormatVersion))
+	}
+	return footer, nil
+}
+
+func (footer Footer) Write(store *Store, offset int64) error {
+	//The way footer is encoded is: 16 bytes for the index block handle | 16 bytes for the properties block handle | 4 bytes for format version | 8 bytes for magic number
+	bytes := make([]byte, footerSize)
+	copy(bytes, footer.IndexHandle.encode())
+	copy(bytes[reservedBlockHandleSize:], footer.PropertiesHandle.encode())
+	bigEndian.PutUint32(bytes[2*reservedBlockHandleSize:], footer.FormatVersion)
+	bigEndian.PutUint64(bytes[footerSize-int64(reservedMagicNumberSize):], footerMagicNumber)
+
+	_, err := store.WriteAt(bytes, offset)
+	return err
+}
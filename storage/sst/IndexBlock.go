@@ -1,96 +1,118 @@
 package sst
 
 import (
+	"errors"
+	"fmt"
+	"sort"
 	"storage-engine-workshop/db/model"
 	"storage-engine-workshop/storage/comparator"
 	"unsafe"
 )
 
-var (
-	ReservedOffsetSize = unsafe.Sizeof(uint64(0))
-)
+var reservedEntryOffsetSize = unsafe.Sizeof(uint32(0))
 
+// IndexBlock is a sparse index with an entry per data block, the entry holds the last key of the block and its handle.
+// The index block ends with a fixed size offset for every entry followed by the number of entries,
+// which lets a lookup binary search the entries without decoding them all.
 type IndexBlock struct {
 	store *Store
 }
 
+type IndexEntry struct {
+	LastKey model.Slice
+	Handle  BlockHandle
+}
+
+// Index is a read-only view over the contents of an index block, it decodes an entry only when it is accessed.
+type Index struct {
+	contents           []byte
+	entryOffsetsOffset int
+	totalEntries       int
+}
+
 func NewIndexBlock(store *Store) *IndexBlock {
 	return &IndexBlock{
 		store: store,
 	}
 }
 
-func (indexBlock *IndexBlock) Write(beginOffsetByKey []int64, blockBeginOffset int64, keyValuePairs []model.KeyValuePair) error {
-	offset, indexBlockBeginOffset := blockBeginOffset, blockBeginOffset
-
-	for index, keyValuePair := range keyValuePairs {
-		bytes := indexBlock.marshal(keyValuePair.Key, beginOffsetByKey[index])
-		if bytesWritten, err := indexBlock.store.WriteAt(bytes, offset); err != nil {
-			return err
-		} else {
-			offset = offset + int64(bytesWritten)
-		}
+func (indexBlock *IndexBlock) Write(indexEntries []IndexEntry, blockBeginOffset int64) (BlockHandle, error) {
+	//The way index block is encoded is: Index entry 0 | ... | Index entry N | 4 bytes offset of every entry | 4 bytes for totalEntries
+	var bytes []byte
+	entryOffsets := make([]byte, int(reservedEntryOffsetSize)*(len(indexEntries)+1))
+	for index, indexEntry := range indexEntries {
+		bigEndian.PutUint32(entryOffsets[index*int(reservedEntryOffsetSize):], uint32(len(bytes)))
+		bytes = append(bytes, indexBlock.marshal(indexEntry)...)
 	}
-	bytes := make([]byte, ReservedOffsetSize)
-	bigEndian.PutUint64(bytes, uint64(indexBlockBeginOffset))
+	bigEndian.PutUint32(entryOffsets[len(indexEntries)*int(reservedEntryOffsetSize):], uint32(len(indexEntries)))
+	bytes = append(bytes, entryOffsets...)
 
-	_, err := indexBlock.store.WriteAt(bytes, offset)
-	return err
-}
-
-func (indexBlock *IndexBlock) GetKeyOffset(key model.Slice, keyComparator comparator.KeyComparator) (int64, error) {
-	blockBytes, err := indexBlock.readIndexBlock()
-	if err != nil {
-		return -1, err
+	if _, err := indexBlock.store.WriteAt(bytes, blockBeginOffset); err != nil {
+		return BlockHandle{}, err
 	}
-	index := 0
-	for index < len(blockBytes) {
-		actualKeySize := bigEndian.Uint32(blockBytes[index:])
-		keyBeginIndex := index + int(reservedKeySize) + int(ReservedOffsetSize)
-		serializedKey := blockBytes[keyBeginIndex : keyBeginIndex+int(actualKeySize)]
-		if keyComparator.Compare(model.NewSlice(serializedKey), key) == 0 {
-			keyOffset := bigEndian.Uint64(blockBytes[(index + int(reservedKeySize)):])
-			return int64(keyOffset), nil
-		}
-		index = index + int(reservedKeySize) + int(ReservedOffsetSize) + int(actualKeySize)
-	}
-	return -1, nil
+	return BlockHandle{Offset: blockBeginOffset, Size: int64(len(bytes))}, nil
 }
 
-func (indexBlock *IndexBlock) readIndexBlock() ([]byte, error) {
-	size, _ := indexBlock.store.Size()
-	offsetContainingIndexBegin := size - int64(ReservedOffsetSize)
-	_, err := indexBlock.store.SeekFromBeginning(offsetContainingIndexBegin)
+func (indexBlock *IndexBlock) Read() (*Index, error) {
+	footer, err := ReadFooter(indexBlock.store)
 	if err != nil {
 		return nil, err
 	}
-	indexBlockBeginOffsetBytes := make([]byte, int(ReservedOffsetSize))
-	_, err = indexBlock.store.ReadAt(indexBlockBeginOffsetBytes, offsetContainingIndexBegin)
-	if err != nil {
-		return nil, err
+	if footer.IndexHandle.Size < int64(reservedEntryOffsetSize) {
+		return nil, errors.New(fmt.Sprintf("index block of size %v in ssTable file %v is corrupt", footer.IndexHandle.Size, indexBlock.store.file.Name()))
 	}
-	indexBlockBeginOffset := bigEndian.Uint64(indexBlockBeginOffsetBytes[0:])
-	blockBytes := make([]byte, offsetContainingIndexBegin-int64(indexBlockBeginOffset))
-	_, err = indexBlock.store.ReadAt(blockBytes, int64(indexBlockBeginOffset))
-	if err != nil {
+	contents := make([]byte, footer.IndexHandle.Size)
+	if _, err := indexBlock.store.ReadAt(contents, footer.IndexHandle.Offset); err != nil {
 		return nil, err
 	}
-	return blockBytes, nil
+	totalEntries := int(bigEndian.Uint32(contents[len(contents)-int(reservedEntryOffsetSize):]))
+	entryOffsetsOffset := len(contents) - int(reservedEntryOffsetSize)*(totalEntries+1)
+	if entryOffsetsOffset < 0 {
+		return nil, errors.New(fmt.Sprintf("index block in ssTable file %v claims %v entries which do not fit in its size", indexBlock.store.file.Name(), totalEntries))
+	}
+	return &Index{contents: contents, entryOffsetsOffset: entryOffsetsOffset, totalEntries: totalEntries}, nil
+}
+
+func (index *Index) Size() int64 {
+	return int64(len(index.contents))
+}
+
+func (index *Index) Len() int {
+	return index.totalEntries
+}
+
+func (index *Index) Entry(position int) IndexEntry {
+	entryOffset := int(bigEndian.Uint32(index.contents[index.entryOffsetsOffset+position*int(reservedEntryOffsetSize):]))
+	actualKeySize := bigEndian.Uint32(index.contents[entryOffset:])
+	handleBeginIndex := entryOffset + int(reservedKeySize)
+	keyBeginIndex := handleBeginIndex + int(reservedBlockHandleSize)
+	return IndexEntry{
+		LastKey: model.NewSlice(index.contents[keyBeginIndex : keyBeginIndex+int(actualKeySize)]),
+		Handle:  decodeBlockHandle(index.contents[handleBeginIndex:]),
+	}
+}
+
+// Search returns the position of the first block whose last key is greater than or equal to the key,
+// which is the only block that may contain the key. It returns Len() if the key is beyond the last block.
+func (index *Index) Search(key model.Slice, keyComparator comparator.KeyComparator) int {
+	return sort.Search(index.totalEntries, func(position int) bool {
+		return keyComparator.Compare(index.Entry(position).LastKey, key) >= 0
+	})
 }
 
-func (indexBlock *IndexBlock) marshal(key model.Slice, keyBeginOffset int64) []byte {
-	actualTotalSize := uint64(reservedKeySize) + uint64(ReservedOffsetSize) + uint64(key.Size())
+func (indexBlock *IndexBlock) marshal(indexEntry IndexEntry) []byte {
+	actualTotalSize := uint64(reservedKeySize) + uint64(reservedBlockHandleSize) + uint64(indexEntry.LastKey.Size())
 
-	//The way index block is encoded is: 4 bytes for keySize | 8 bytes for offsetSize | Key content
+	//The way index entry is encoded is: 4 bytes for keySize | 16 bytes for block handle | Last key content
 	bytes := make([]byte, actualTotalSize)
 	index := 0
 
-	bigEndian.PutUint32(bytes[index:], uint32(key.Size()))
+	bigEndian.PutUint32(bytes[index:], uint32(indexEntry.LastKey.Size()))
 	index = index + int(reservedKeySize)
 
-	bigEndian.PutUint64(bytes[index:], uint64(keyBeginOffset))
-	index = index + int(ReservedOffsetSize)
+	copy(bytes[index:], indexEntry.Handle.encode())
+	index = index + int(reservedBlockHandleSize)
 
-	copy(bytes[index:], key.GetRawContent())
+	copy(bytes[index:], indexEntry.LastKey.GetRawContent())
 	return bytes
 }
@@ -0,0 +1,68 @@
+package sst
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"testing"
+)
+
+type countingKeyComparator struct {
+	comparisons int
+}
+
+func (keyComparator *countingKeyComparator) Name() string {
+	return "countingKeyComparator"
+}
+
+func (keyComparator *countingKeyComparator) Compare(one model.Slice, other model.Slice) int {
+	keyComparator.comparisons = keyComparator.comparisons + 1
+	return comparator.StringKeyComparator{}.Compare(one, other)
+}
+
+func writeIndexWith(totalEntries int, directory string) *Index {
+	store, _ := NewStore(path.Join(directory, "index.sst"))
+	var indexEntries []IndexEntry
+	for count := 0; count < totalEntries; count++ {
+		indexEntries = append(indexEntries, IndexEntry{
+			LastKey: model.NewSlice([]byte(fmt.Sprintf("Key-%06d", count))),
+			Handle:  BlockHandle{Offset: int64(count * 100), Size: 100},
+		})
+	}
+	indexHandle, _ := NewIndexBlock(store).Write(indexEntries, 0)
+	_ = Footer{IndexHandle: indexHandle, FormatVersion: currentFormatVersion}.Write(store, indexHandle.Size)
+	index, _ := NewIndexBlock(store).Read()
+	return index
+}
+
+func TestSearchesTheIndexWithALogarithmicNumberOfComparisons(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	index := writeIndexWith(10000, directory)
+	keyComparator := &countingKeyComparator{}
+
+	position := index.Search(model.NewSlice([]byte("Key-007000")), keyComparator)
+	if position != 7000 || index.Entry(position).Handle.Offset != 700000 {
+		t.Fatalf("Expected search to find position %v, received %v", 7000, position)
+	}
+	if keyComparator.comparisons > 15 {
+		t.Fatalf("Expected at most %v comparisons, received %v", 15, keyComparator.comparisons)
+	}
+}
+
+func TestSearchesTheIndexForAKeyBetweenOrBeyondTheLastKeys(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	index := writeIndexWith(10, directory)
+
+	if position := index.Search(model.NewSlice([]byte("Key-000003-Between")), comparator.StringKeyComparator{}); position != 4 {
+		t.Fatalf("Expected search to find position %v, received %v", 4, position)
+	}
+	if position := index.Search(model.NewSlice([]byte("Key-999999")), comparator.StringKeyComparator{}); position != index.Len() {
+		t.Fatalf("Expected search to find position %v, received %v", index.Len(), position)
+	}
+}
@@ -0,0 +1,176 @@
+package sst
+
+import (
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+)
+
+const (
+	defaultLevel0FileTrigger    = 4
+	defaultMaxBytesForLevelBase = 10 * 1024 * 1024
+	defaultLevelSizeMultiplier  = 10
+	defaultMaxLevels            = 7
+	defaultTargetFileSize       = 2 * 1024 * 1024
+)
+
+// LeveledCompactionOptions configure leveled compaction, zero values leave the defaults in place.
+// Flushed ssTables land in level 0 where their key ranges may overlap, every deeper level is a run of ssTables with disjoint key ranges.
+// Level 1 may hold MaxBytesForLevelBase bytes and every level after it LevelSizeMultiplier times the level before it,
+// level 0 may hold Level0FileTrigger ssTables. The level which exceeds its limit by the largest factor is compacted into the next level,
+// which is split into ssTables of roughly TargetFileSize bytes. MaxLevels is the number of levels including level 0.
+type LeveledCompactionOptions struct {
+	Level0FileTrigger    int
+	MaxBytesForLevelBase int64
+	LevelSizeMultiplier  int
+	MaxLevels            int
+	TargetFileSize       int64
+}
+
+func (options LeveledCompactionOptions) level0FileTriggerOrDefault() int {
+	if options.Level0FileTrigger <= 0 {
+		return defaultLevel0FileTrigger
+	}
+	return options.Level0FileTrigger
+}
+
+func (options LeveledCompactionOptions) maxBytesForLevelBaseOrDefault() int64 {
+	if options.MaxBytesForLevelBase <= 0 {
+		return defaultMaxBytesForLevelBase
+	}
+	return options.MaxBytesForLevelBase
+}
+
+func (options LeveledCompactionOptions) levelSizeMultiplierOrDefault() int {
+	if options.LevelSizeMultiplier < 2 {
+		return defaultLevelSizeMultiplier
+	}
+	return options.LevelSizeMultiplier
+}
+
+func (options LeveledCompactionOptions) maxLevelsOrDefault() int {
+	if options.MaxLevels < 2 {
+		return defaultMaxLevels
+	}
+	return options.MaxLevels
+}
+
+func (options LeveledCompactionOptions) targetFileSizeOrDefault() int64 {
+	if options.TargetFileSize <= 0 {
+		return defaultTargetFileSize
+	}
+	return options.TargetFileSize
+}
+
+// backlog is the number of ssTables in level 0, every one of them waits to be merged into level 1.
+func (options LeveledCompactionOptions) backlog(levels [][]*SSTable) int {
+	return len(levels[0])
+}
+
+// maxBytesFor answers the size limit of a level deeper than 0.
+func (options LeveledCompactionOptions) maxBytesFor(level int) float64 {
+	maxBytes := float64(options.maxBytesForLevelBaseOrDefault())
+	for deeperLevel := 2; deeperLevel <= level; deeperLevel++ {
+		maxBytes = maxBytes * float64(options.levelSizeMultiplierOrDefault())
+	}
+	return maxBytes
+}
+
+// score answers how many times a level exceeds its limit, a level with a score of at least 1 needs a compaction.
+func (options LeveledCompactionOptions) score(levels [][]*SSTable, level int) float64 {
+	if level == 0 {
+		return float64(len(levels[0])) / float64(options.level0FileTriggerOrDefault())
+	}
+	var totalSize int64
+	for _, table := range levels[level] {
+		totalSize = totalSize + table.Size()
+	}
+	return float64(totalSize) / options.maxBytesFor(level)
+}
+
+// pick compacts the level with the highest score into the next level. A level 0 compaction merges all the ssTables of level 0,
+// any other level merges its oldest ssTable. Both merge the ssTables of the next level which overlap the key range of the ssTables being pushed down.
+func (options LeveledCompactionOptions) pick(levels [][]*SSTable, keyComparator comparator.KeyComparator) *compaction {
+	pickedLevel, highestScore := -1, 1.0
+	for level := 0; level < len(levels) && level < options.maxLevelsOrDefault()-1; level++ {
+		if score := options.score(levels, level); score >= highestScore {
+			pickedLevel, highestScore = level, score
+		}
+	}
+	if pickedLevel < 0 {
+		return nil
+	}
+	var inputs []*SSTable
+	if pickedLevel == 0 {
+		inputs = newestFirst(levels[0])
+	} else {
+		oldest := levels[pickedLevel][0]
+		for _, table := range levels[pickedLevel] {
+			if table.generation < oldest.generation {
+				oldest = table
+			}
+		}
+		inputs = []*SSTable{oldest}
+	}
+	if pickedLevel+1 < len(levels) {
+		smallestKey, largestKey := keyRangeOf(inputs, keyComparator)
+		for _, table := range levels[pickedLevel+1] {
+			if table.overlapsKeyRange(smallestKey, largestKey, keyComparator) {
+				inputs = append(inputs, table)
+			}
+		}
+	}
+	return &compaction{inputs: inputs, outputLevel: pickedLevel + 1, targetFileSize: options.targetFileSizeOrDefault()}
+}
+
+// pickRange pushes the ssTables of the level which overlap [lowerBound, upperBound) into the next level, merging them with the ssTables
+// of the next level which overlap them. All of level 0 is pushed, so that no older level 0 ssTable is left to shadow the pushed ssTables.
+// The deepest level which has ssTables is rewritten in place.
+func (options LeveledCompactionOptions) pickRange(levels [][]*SSTable, level int, lowerBound, upperBound model.Slice, keyComparator comparator.KeyComparator) *compaction {
+	var inputs []*SSTable
+	for _, table := range levels[level] {
+		if table.Overlaps(lowerBound, upperBound, keyComparator) {
+			inputs = append(inputs, table)
+		}
+	}
+	if len(inputs) == 0 {
+		return nil
+	}
+	if level == 0 {
+		inputs = newestFirst(levels[0])
+	}
+	isDeepest := level > 0 && (level == options.maxLevelsOrDefault()-1 || isEmptyBelow(levels, level))
+	if isDeepest {
+		return &compaction{inputs: inputs, outputLevel: level, targetFileSize: options.targetFileSizeOrDefault()}
+	}
+	if level+1 < len(levels) {
+		smallestKey, largestKey := keyRangeOf(inputs, keyComparator)
+		for _, table := range levels[level+1] {
+			if table.overlapsKeyRange(smallestKey, largestKey, keyComparator) {
+				inputs = append(inputs, table)
+			}
+		}
+	}
+	return &compaction{inputs: inputs, outputLevel: level + 1, targetFileSize: options.targetFileSizeOrDefault()}
+}
+
+func isEmptyBelow(levels [][]*SSTable, level int) bool {
+	for deeperLevel := level + 1; deeperLevel < len(levels); deeperLevel++ {
+		if len(levels[deeperLevel]) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func keyRangeOf(tables []*SSTable, keyComparator comparator.KeyComparator) (model.Slice, model.Slice) {
+	smallestKey, largestKey := tables[0].smallestKey, tables[0].largestKey
+	for _, table := range tables[1:] {
+		if keyComparator.Compare(table.smallestKey, smallestKey) < 0 {
+			smallestKey = table.smallestKey
+		}
+		if keyComparator.Compare(table.largestKey, largestKey) > 0 {
+			largestKey = table.largestKey
+		}
+	}
+	return smallestKey, largestKey
+}
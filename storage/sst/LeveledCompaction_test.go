@@ -0,0 +1,126 @@
+package sst
+
+import (
+	"os"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"strconv"
+	"testing"
+)
+
+func assertDisjointAndSorted(t *testing.T, tables []*SSTable) {
+	for index := 1; index < len(tables); index++ {
+		if (comparator.StringKeyComparator{}).Compare(tables[index-1].largestKey, tables[index].smallestKey) >= 0 {
+			t.Fatalf("Expected ssTables of a level to be sorted with disjoint key ranges, received %v after %v", tables[index].smallestKey.AsString(), tables[index-1].largestKey.AsString())
+		}
+	}
+}
+
+func TestCompactsLevel0IntoLevel1SplitByTheTargetFileSize(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{Compaction: LeveledCompactionOptions{Level0FileTrigger: 4, TargetFileSize: 200}})
+	for table := 1; table <= 4; table++ {
+		keyValuePairs := make(map[string]string)
+		for count := 0; count < 25; count++ {
+			keyValuePairs["Key-"+strconv.Itoa(table*100+count)] = "Value-" + strconv.Itoa(table)
+		}
+		keyValuePairs["Company"] = "TW-" + strconv.Itoa(table)
+		publishSSTableWith(ssTables, keyValuePairs)
+	}
+
+	if compacted, err := ssTables.Compact(comparator.StringKeyComparator{}); !compacted || err != nil {
+		t.Fatalf("Expected level 0 to be compacted, received %v with error %v", compacted, err)
+	}
+	if len(ssTables.levels[0]) != 0 {
+		t.Fatalf("Expected level 0 to be empty after compaction, received %v ssTables", len(ssTables.levels[0]))
+	}
+	if len(ssTables.levels[1]) < 2 {
+		t.Fatalf("Expected level 1 to be split into multiple ssTables, received %v", len(ssTables.levels[1]))
+	}
+	assertDisjointAndSorted(t, ssTables.levels[1])
+
+	if getResult := ssTables.Get(model.NewSlice([]byte("Company")), comparator.StringKeyComparator{}); getResult.Value.AsString() != "TW-4" {
+		t.Fatalf("Expected value to be %v, received %v", "TW-4", getResult.Value.AsString())
+	}
+	for table := 1; table <= 4; table++ {
+		key := "Key-" + strconv.Itoa(table*100+24)
+		if getResult := ssTables.Get(model.NewSlice([]byte(key)), comparator.StringKeyComparator{}); getResult.Value.AsString() != "Value-"+strconv.Itoa(table) {
+			t.Fatalf("Expected value to be %v, received %v", "Value-"+strconv.Itoa(table), getResult.Value.AsString())
+		}
+	}
+}
+
+func TestDoesNotCompactLevel0BelowTheFileTrigger(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{Compaction: LeveledCompactionOptions{Level0FileTrigger: 4}})
+	for table := 1; table <= 3; table++ {
+		publishSSTableWith(ssTables, map[string]string{"Key-" + strconv.Itoa(table): "Value-" + strconv.Itoa(table)})
+	}
+	if compacted, _ := ssTables.Compact(comparator.StringKeyComparator{}); compacted {
+		t.Fatalf("Expected %v ssTables in level 0 not to be compacted", 3)
+	}
+}
+
+func TestCompactsALevelExceedingItsSizeIntoTheNextLevel(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{Compaction: LeveledCompactionOptions{Level0FileTrigger: 2, MaxBytesForLevelBase: 100}})
+	publishSSTableWith(ssTables, map[string]string{"Key-1": "Value-1", "Key-3": "Value-3"})
+	publishSSTableWith(ssTables, map[string]string{"Key-2": "Value-2", "Key-3": "Value-33"})
+	_, _ = ssTables.Compact(comparator.StringKeyComparator{})
+
+	if compacted, _ := ssTables.Compact(comparator.StringKeyComparator{}); !compacted {
+		t.Fatalf("Expected level 1 exceeding its size to be compacted")
+	}
+	if len(ssTables.levels[1]) != 0 || len(ssTables.levels[2]) != 1 {
+		t.Fatalf("Expected level 1 to be compacted into level 2, received %v ssTables in level 1 and %v in level 2", len(ssTables.levels[1]), len(ssTables.levels[2]))
+	}
+	if getResult := ssTables.Get(model.NewSlice([]byte("Key-3")), comparator.StringKeyComparator{}); getResult.Value.AsString() != "Value-33" {
+		t.Fatalf("Expected value to be %v, received %v", "Value-33", getResult.Value.AsString())
+	}
+}
+
+func TestGetsTheNewestVersionFromLevel0BeforeTheDeeperLevels(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{Compaction: LeveledCompactionOptions{Level0FileTrigger: 2}})
+	publishSSTableWith(ssTables, map[string]string{"Company": "TW-1"})
+	publishSSTableWith(ssTables, map[string]string{"Company": "TW-2"})
+	_, _ = ssTables.Compact(comparator.StringKeyComparator{})
+	publishSSTableWith(ssTables, map[string]string{"Company": "TW-3"})
+
+	if getResult := ssTables.Get(model.NewSlice([]byte("Company")), comparator.StringKeyComparator{}); getResult.Value.AsString() != "TW-3" {
+		t.Fatalf("Expected value to be %v, received %v", "TW-3", getResult.Value.AsString())
+	}
+	multiGetResult := ssTables.MultiGet([]model.Slice{model.NewSlice([]byte("Company"))}, comparator.StringKeyComparator{})
+	if value := multiGetResult.Values[0].Value.AsString(); value != "TW-3" {
+		t.Fatalf("Expected value to be %v, received %v", "TW-3", value)
+	}
+}
+
+func TestSearchesAtMostOneSSTableInALevelBelowLevel0(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{Compaction: LeveledCompactionOptions{Level0FileTrigger: 1, TargetFileSize: 10}})
+	keyValuePairs := make(map[string]string)
+	for count := 0; count < 64; count++ {
+		keyValuePairs["Key-"+strconv.Itoa(1000+count)] = "Value-" + strconv.Itoa(count)
+	}
+	publishSSTableWith(ssTables, keyValuePairs)
+	_, _ = ssTables.Compact(comparator.StringKeyComparator{})
+
+	keyComparator := &countingKeyComparator{}
+	if getResult := ssTables.Get(model.NewSlice([]byte("Key-1040")), keyComparator); getResult.Value.AsString() != "Value-40" {
+		t.Fatalf("Expected value to be %v, received %v", "Value-40", getResult.Value.AsString())
+	}
+	if keyComparator.comparisons > 16 {
+		t.Fatalf("Expected at most %v comparisons across %v ssTables, received %v", 16, len(ssTables.levels[1]), keyComparator.comparisons)
+	}
+}
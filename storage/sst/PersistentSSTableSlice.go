@@ -7,9 +7,9 @@ import (
 )
 
 var (
-	bigEndian         = binary.BigEndian
-	reservedTotalSize = unsafe.Sizeof(uint32(0))
-	reservedKeySize   = unsafe.Sizeof(uint32(0))
+	bigEndian             = binary.BigEndian
+	reservedKeySize       = unsafe.Sizeof(uint32(0))
+	reservedExpiresAtSize = unsafe.Sizeof(int64(0))
 )
 
 type PersistentSSTableSlice struct {
@@ -21,11 +21,17 @@ var emptyPersistentSSTableSlice = PersistentSSTableSlice{contents: []byte{}}
 func EmptyPersistentSSTableSlice() PersistentSSTableSlice {
 	return emptyPersistentSSTableSlice
 }
-func NewPersistentSSTableSlice(keyValuePair model.KeyValuePair) PersistentSSTableSlice {
-	return marshal(keyValuePair)
+
+// NewPersistentSSTableSlice encodes the keyValuePair leaving out the first sharedKeySize bytes of the key,
+// which the key shares with the key encoded before it. The sizes are encoded as varints, as LevelDB does, so that small keys and values
+// take a byte for every size.
+func NewPersistentSSTableSlice(keyValuePair model.KeyValuePair, sharedKeySize int) PersistentSSTableSlice {
+	return marshal(keyValuePair, sharedKeySize)
 }
 
-func NewPersistentSSTableSliceKeyValuePair(contents []byte) (PersistentSSTableSlice, PersistentSSTableSlice) {
+// NewPersistentSSTableSliceKeyValuePair decodes the size of the shared key prefix, the unshared part of the key, the value and expiresAt
+// of the keyValuePair at the beginning of contents, along with the size of its encoding.
+func NewPersistentSSTableSliceKeyValuePair(contents []byte) (int, PersistentSSTableSlice, PersistentSSTableSlice, int64, int) {
 	return unmarshal(contents)
 }
 
@@ -41,39 +47,41 @@ func (persistentLogSlice PersistentSSTableSlice) Size() int {
 	return len(persistentLogSlice.contents)
 }
 
-func ActualTotalSize(bytes []byte) uint32 {
-	return bigEndian.Uint32(bytes)
-}
-
-func marshal(keyValuePair model.KeyValuePair) PersistentSSTableSlice {
-	reservedTotalSize, reservedKeySize := reservedTotalSize, reservedKeySize
-	actualTotalSize :=
-		len(keyValuePair.Key.GetRawContent()) +
-			len(keyValuePair.Value.GetRawContent()) +
-			int(reservedKeySize) +
-			int(reservedTotalSize)
-
-	//The way keyValuePair is encoded is: 4 bytes for totalSize | 4 bytes for keySize | Key content | Value content
-	bytes := make([]byte, actualTotalSize)
+func marshal(keyValuePair model.KeyValuePair, sharedKeySize int) PersistentSSTableSlice {
+	unsharedKey := keyValuePair.Key.GetRawContent()[sharedKeySize:]
+	value := keyValuePair.Value.GetRawContent()
+	maxTotalSize :=
+		len(unsharedKey) +
+			len(value) +
+			3*binary.MaxVarintLen32 +
+			int(reservedExpiresAtSize)
+
+	//The way keyValuePair is encoded is: varint sharedKeySize | varint unsharedKeySize | varint valueSize | 8 bytes for expiresAt | Unshared key content | Value content
+	bytes := make([]byte, maxTotalSize)
 	offset := 0
 
-	bigEndian.PutUint32(bytes, uint32(actualTotalSize))
-	offset = offset + int(reservedTotalSize)
-
-	bigEndian.PutUint32(bytes[offset:], uint32(len(keyValuePair.Key.GetRawContent())))
-	offset = offset + int(reservedKeySize)
+	offset = offset + binary.PutUvarint(bytes[offset:], uint64(sharedKeySize))
+	offset = offset + binary.PutUvarint(bytes[offset:], uint64(len(unsharedKey)))
+	offset = offset + binary.PutUvarint(bytes[offset:], uint64(len(value)))
 
-	copy(bytes[offset:], keyValuePair.Key.GetRawContent())
-	offset = offset + len(keyValuePair.Key.GetRawContent())
+	bigEndian.PutUint64(bytes[offset:], uint64(keyValuePair.ExpiresAt))
+	offset = offset + int(reservedExpiresAtSize)
 
-	copy(bytes[offset:], keyValuePair.Value.GetRawContent())
-	return PersistentSSTableSlice{contents: bytes}
+	offset = offset + copy(bytes[offset:], unsharedKey)
+	offset = offset + copy(bytes[offset:], value)
+	return PersistentSSTableSlice{contents: bytes[:offset]}
 }
 
-func unmarshal(bytes []byte) (PersistentSSTableSlice, PersistentSSTableSlice) {
-	bytes = bytes[reservedTotalSize:]
-	keySize := bigEndian.Uint32(bytes)
-	keyEndOffset := uint32(reservedKeySize) + keySize
-
-	return PersistentSSTableSlice{contents: bytes[reservedKeySize:keyEndOffset]}, PersistentSSTableSlice{contents: bytes[keyEndOffset:]}
+func unmarshal(bytes []byte) (int, PersistentSSTableSlice, PersistentSSTableSlice, int64, int) {
+	sharedKeySize, offset := binary.Uvarint(bytes)
+	unsharedKeySize, size := binary.Uvarint(bytes[offset:])
+	offset = offset + size
+	valueSize, size := binary.Uvarint(bytes[offset:])
+	offset = offset + size
+	expiresAt := int64(bigEndian.Uint64(bytes[offset:]))
+	keyBeginOffset := offset + int(reservedExpiresAtSize)
+	keyEndOffset := keyBeginOffset + int(unsharedKeySize)
+	valueEndOffset := keyEndOffset + int(valueSize)
+
+	return int(sharedKeySize), PersistentSSTableSlice{contents: bytes[keyBeginOffset:keyEndOffset]}, PersistentSSTableSlice{contents: bytes[keyEndOffset:valueEndOffset]}, expiresAt, valueEndOffset
 }
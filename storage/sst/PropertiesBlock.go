@@ -0,0 +1,115 @@
+package sst
+
+import (
+	"errors"
+	"fmt"
+	"storage-engine-workshop/db/model"
+	"time"
+	"unsafe"
+)
+
+var (
+	reservedPropertySize       = unsafe.Sizeof(uint64(0))
+	reservedPropertyLengthSize = unsafe.Sizeof(uint32(0))
+)
+
+// Properties describe the contents of an ssTable, they are written once along with the ssTable.
+type Properties struct {
+	TotalEntries     uint64
+	RawKeySize       uint64
+	RawValueSize     uint64
+	SmallestKey      model.Slice
+	LargestKey       model.Slice
+	SmallestSequence uint64
+	LargestSequence  uint64
+	CreatedAt        time.Time
+	ComparatorName   string
+}
+
+// PropertiesBlock holds the properties of an ssTable between its index block and its footer.
+type PropertiesBlock struct {
+	store *Store
+}
+
+func NewPropertiesBlock(store *Store) *PropertiesBlock {
+	return &PropertiesBlock{
+		store: store,
+	}
+}
+
+func (propertiesBlock *PropertiesBlock) Write(properties Properties, blockBeginOffset int64) (BlockHandle, error) {
+	//The way properties block is encoded is: 8 bytes each for totalEntries, rawKeySize, rawValueSize, smallestSequence, largestSequence and createdAt |
+	//4 bytes for smallestKey size | smallestKey | 4 bytes for largestKey size | largestKey | 4 bytes for comparatorName size | comparatorName
+	bytes := make([]byte, 6*int(reservedPropertySize))
+	for index, property := range []uint64{
+		properties.TotalEntries,
+		properties.RawKeySize,
+		properties.RawValueSize,
+		properties.SmallestSequence,
+		properties.LargestSequence,
+		uint64(properties.CreatedAt.UnixNano()),
+	} {
+		bigEndian.PutUint64(bytes[index*int(reservedPropertySize):], property)
+	}
+	for _, variableSizeProperty := range [][]byte{
+		properties.SmallestKey.GetRawContent(),
+		properties.LargestKey.GetRawContent(),
+		[]byte(properties.ComparatorName),
+	} {
+		size := make([]byte, reservedPropertyLengthSize)
+		bigEndian.PutUint32(size, uint32(len(variableSizeProperty)))
+		bytes = append(bytes, size...)
+		bytes = append(bytes, variableSizeProperty...)
+	}
+
+	if _, err := propertiesBlock.store.WriteAt(bytes, blockBeginOffset); err != nil {
+		return BlockHandle{}, err
+	}
+	return BlockHandle{Offset: blockBeginOffset, Size: int64(len(bytes))}, nil
+}
+
+func (propertiesBlock *PropertiesBlock) Read() (Properties, error) {
+	footer, err := ReadFooter(propertiesBlock.store)
+	if err != nil {
+		return Properties{}, err
+	}
+	corruptError := func() error {
+		return errors.New(fmt.Sprintf("properties block of size %v in ssTable file %v is corrupt", footer.PropertiesHandle.Size, propertiesBlock.store.file.Name()))
+	}
+	if footer.PropertiesHandle.Size < int64(6*reservedPropertySize) {
+		return Properties{}, corruptError()
+	}
+	bytes := make([]byte, footer.PropertiesHandle.Size)
+	if _, err := propertiesBlock.store.ReadAt(bytes, footer.PropertiesHandle.Offset); err != nil {
+		return Properties{}, err
+	}
+	fixedSizeProperties := make([]uint64, 6)
+	for index := range fixedSizeProperties {
+		fixedSizeProperties[index] = bigEndian.Uint64(bytes[index*int(reservedPropertySize):])
+	}
+	offset := 6 * int(reservedPropertySize)
+	variableSizeProperties := make([][]byte, 3)
+	for index := range variableSizeProperties {
+		if offset+int(reservedPropertyLengthSize) > len(bytes) {
+			return Properties{}, corruptError()
+		}
+		size := int(bigEndian.Uint32(bytes[offset:]))
+		offset = offset + int(reservedPropertyLengthSize)
+		if offset+size > len(bytes) {
+			return Properties{}, corruptError()
+		}
+		variableSizeProperties[index] = bytes[offset : offset+size]
+		offset = offset + size
+	}
+	return Properties{
+		TotalEntries:     fixedSizeProperties[0],
+		RawKeySize:       fixedSizeProperties[1],
+		RawValueSize:     fixedSizeProperties[2],
+		SmallestSequence: fixedSizeProperties[3],
+		LargestSequence:  fixedSizeProperties[4],
+		CreatedAt:        time.Unix(0, int64(fixedSizeProperties[5])),
+		SmallestKey:      model.NewSlice(variableSizeProperties[0]),
+		LargestKey:       model.NewSlice(variableSizeProperties[1]),
+		ComparatorName:   string(variableSizeProperties[2]),
+	}, nil
+}
@@ -0,0 +1,52 @@
+package sst
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket which refills bytesPerSecond tokens every second and holds at most a second worth of tokens.
+// A request for more tokens than are available borrows the tokens to come and waits till they are refilled,
+// so that the requests are paced at bytesPerSecond however large a single request is. A nil RateLimiter does not limit.
+type RateLimiter struct {
+	bytesPerSecond int64
+	tokens         float64
+	lastRefill     time.Time
+	lock           sync.Mutex
+}
+
+// NewRateLimiter returns nil if bytesPerSecond is not positive.
+func NewRateLimiter(bytesPerSecond int64) *RateLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return &RateLimiter{bytesPerSecond: bytesPerSecond, tokens: float64(bytesPerSecond), lastRefill: time.Now()}
+}
+
+// Request blocks till the bytes fit in the rate, a request for 0 bytes waits till the tokens borrowed by the earlier requests are refilled.
+func (rateLimiter *RateLimiter) Request(bytes int) {
+	if rateLimiter == nil {
+		return
+	}
+	time.Sleep(rateLimiter.reserve(bytes, time.Now()))
+}
+
+// reserve takes the bytes out of the bucket and answers how long the caller has to wait for the borrowed tokens.
+func (rateLimiter *RateLimiter) reserve(bytes int, now time.Time) time.Duration {
+	rateLimiter.lock.Lock()
+	defer rateLimiter.lock.Unlock()
+
+	elapsed := now.Sub(rateLimiter.lastRefill)
+	if elapsed > 0 {
+		rateLimiter.tokens = rateLimiter.tokens + elapsed.Seconds()*float64(rateLimiter.bytesPerSecond)
+		if rateLimiter.tokens > float64(rateLimiter.bytesPerSecond) {
+			rateLimiter.tokens = float64(rateLimiter.bytesPerSecond)
+		}
+		rateLimiter.lastRefill = now
+	}
+	rateLimiter.tokens = rateLimiter.tokens - float64(bytes)
+	if rateLimiter.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-rateLimiter.tokens / float64(rateLimiter.bytesPerSecond) * float64(time.Second))
+}
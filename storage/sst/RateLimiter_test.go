@@ -0,0 +1,64 @@
+package sst
+
+import (
+	"os"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"storage-engine-workshop/storage/memory"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBorrowsTheTokensToComeForARequestLargerThanTheAvailableTokens(t *testing.T) {
+	rateLimiter := NewRateLimiter(1000)
+	now := rateLimiter.lastRefill
+
+	if wait := rateLimiter.reserve(600, now); wait != 0 {
+		t.Fatalf("Expected a request within the available tokens not to wait, received %v", wait)
+	}
+	if wait := rateLimiter.reserve(900, now); wait != 500*time.Millisecond {
+		t.Fatalf("Expected to wait %v for the borrowed tokens, received %v", 500*time.Millisecond, wait)
+	}
+	if wait := rateLimiter.reserve(0, now.Add(250*time.Millisecond)); wait != 250*time.Millisecond {
+		t.Fatalf("Expected to wait %v for the tokens borrowed earlier, received %v", 250*time.Millisecond, wait)
+	}
+}
+
+func TestRefillsAtMostASecondWorthOfTokens(t *testing.T) {
+	rateLimiter := NewRateLimiter(1000)
+	now := rateLimiter.lastRefill.Add(time.Minute)
+
+	if wait := rateLimiter.reserve(1000, now); wait != 0 {
+		t.Fatalf("Expected a request of a second worth of tokens not to wait, received %v", wait)
+	}
+	if wait := rateLimiter.reserve(100, now); wait != 100*time.Millisecond {
+		t.Fatalf("Expected to wait %v, received %v", 100*time.Millisecond, wait)
+	}
+}
+
+func TestDoesNotLimitWithoutARate(t *testing.T) {
+	if rateLimiter := NewRateLimiter(0); rateLimiter != nil {
+		t.Fatalf("Expected no rate limiter without a rate")
+	}
+	var rateLimiter *RateLimiter
+	rateLimiter.Request(1024 * 1024)
+}
+
+func TestWritesSSTablesAtTheRateLimit(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{RateLimitBytesPerSecond: 2048})
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	for count := 0; count < 100; count++ {
+		memTable.Put(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+	}
+	ssTable, _ := ssTables.NewSSTable(memTable)
+
+	start := time.Now()
+	_ = ssTable.Write()
+	if elapsed, minimum := time.Since(start), time.Duration(float64(ssTable.Size()-2048)/2048*float64(time.Second)); elapsed < minimum {
+		t.Fatalf("Expected writing %v bytes to take at least %v, took %v", ssTable.Size(), minimum, elapsed)
+	}
+}
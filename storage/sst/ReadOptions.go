@@ -0,0 +1,13 @@
+package sst
+
+import "storage-engine-workshop/db/model"
+
+// ReadOptions tunes a single read, the zero value fills the block cache with the data blocks that the read loads.
+// A large scan sets SkipFillingCache so that it does not evict the blocks of hot keys.
+// LowerBound and UpperBound limit a scan to [LowerBound, UpperBound), an empty bound leaves that side open,
+// the ssTables whose key range lies outside the bounds are not scanned.
+type ReadOptions struct {
+	SkipFillingCache bool
+	LowerBound       model.Slice
+	UpperBound       model.Slice
+}
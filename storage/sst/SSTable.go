@@ -1,105 +1,306 @@
 package sst
 
 import (
-	"errors"
 	"fmt"
+	"log"
 	"path"
 	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/cache"
 	"storage-engine-workshop/storage/comparator"
+	"storage-engine-workshop/storage/compression"
+	"storage-engine-workshop/storage/extractor"
 	"storage-engine-workshop/storage/filter"
+	"storage-engine-workshop/storage/iterator"
 	"storage-engine-workshop/storage/memory"
 	"strconv"
+	"sync/atomic"
+	"time"
 )
 
+// SSTable is published for search with a reference held by SSTables, every snapshot and iterator holds its own reference.
+// Once compaction replaces the ssTable, its files are deleted when the last reference is released.
+// The generation orders ssTables from the oldest to the newest, it is the file id for a flushed ssTable
+// and the generation of the newest input for a compacted ssTable. Similarly, createdAt is the time a flushed ssTable is written
+// and the creation time of the newest input for a compacted ssTable, which makes it the age of the newest data in the ssTable.
 type SSTable struct {
-	store         *Store
-	keyValuePairs []model.KeyValuePair
-	bloomFilter   *filter.BloomFilter
+	fileId           int
+	generation       int
+	store            *Store
+	keyValuePairs    []model.KeyValuePair
+	bloomFilter      *filter.BloomFilter
+	bloomFilters     *filter.BloomFilters
+	indexCache       *cache.LRUCache
+	blockCache       *cache.ShardedLRUCache
+	prefixExtractor  extractor.PrefixExtractor
+	blockSize        int
+	restartInterval  int
+	compression      compression.Codec
+	smallestSequence uint64
+	largestSequence  uint64
+	comparatorName   string
+	smallestKey      model.Slice
+	largestKey       model.Slice
+	createdAt        time.Time
+	size             int64
+	references       int32
 }
 
-func NewSSTableFrom(memTable *memory.MemTable, bloomFilters *filter.BloomFilters, directory string, fileId int) (*SSTable, error) {
+func NewSSTableFrom(memTable *memory.MemTable, bloomFilters *filter.BloomFilters, indexCache *cache.LRUCache, blockCache *cache.ShardedLRUCache, directory string, fileId int, options SSTableOptions) (*SSTable, error) {
+	smallestSequence, largestSequence := memTable.SequenceRange()
+	now := time.Now()
+	keyValuePairs := filteredKeyValues(expiredAsTombstones(memTable.AllKeyValues(), now), options.CompactionFilter, now, anyOlderVersion)
+	ssTable, err := newSSTable(keyValuePairs, bloomFilters, indexCache, blockCache, directory, fileId, options)
+	if err != nil {
+		return nil, err
+	}
+	ssTable.smallestSequence, ssTable.largestSequence = smallestSequence, largestSequence
+	ssTable.comparatorName = memTable.KeyComparator().Name()
+	return ssTable, nil
+}
+
+// newSSTable expects the keyValuePairs sorted by key, without duplicate keys.
+func newSSTable(keyValuePairs []model.KeyValuePair, bloomFilters *filter.BloomFilters, indexCache *cache.LRUCache, blockCache *cache.ShardedLRUCache, directory string, fileId int, options SSTableOptions) (*SSTable, error) {
+	ssTable, err := newSSTableWithCapacity(len(keyValuePairs), bloomFilters, indexCache, blockCache, directory, fileId, options)
+	if err != nil {
+		return nil, err
+	}
+	ssTable.keyValuePairs = keyValuePairs
+	if len(keyValuePairs) > 0 {
+		ssTable.smallestKey, ssTable.largestKey = keyValuePairs[0].Key, keyValuePairs[len(keyValuePairs)-1].Key
+	}
+	return ssTable, nil
+}
+
+// newSSTableWithCapacity creates an ssTable whose key/value pairs are added to an ssTableWriter, the bloom filter is sized for capacity keys.
+func newSSTableWithCapacity(capacity int, bloomFilters *filter.BloomFilters, indexCache *cache.LRUCache, blockCache *cache.ShardedLRUCache, directory string, fileId int, options SSTableOptions) (*SSTable, error) {
 	store, err := NewStore(path.Join(directory, fmt.Sprintf("%v.sst", fileId)))
 	if err != nil {
 		return nil, err
 	}
-	bloomFilter, err := createBloomFilter(fileId, memTable.TotalKeys(), bloomFilters)
+	store.rateLimiter = options.rateLimiter
+	if options.PrefixExtractor != nil {
+		capacity = capacity * 2
+	}
+	bloomFilter, err := createBloomFilter(fileId, capacity, bloomFilters)
 	if err != nil {
 		return nil, err
 	}
+	bloomFilter.Acquire()
 	return &SSTable{
-		store:         store,
-		keyValuePairs: memTable.AllKeyValues(),
-		bloomFilter:   bloomFilter,
+		fileId:          fileId,
+		generation:      fileId,
+		store:           store,
+		bloomFilter:     bloomFilter,
+		bloomFilters:    bloomFilters,
+		indexCache:      indexCache,
+		blockCache:      blockCache,
+		prefixExtractor: options.PrefixExtractor,
+		blockSize:       options.blockSizeOrDefault(),
+		restartInterval: options.restartIntervalOrDefault(),
+		compression:     options.compressionOrDefault(),
+		smallestKey:     model.NilSlice(),
+		largestKey:      model.NilSlice(),
 	}, nil
 }
 
+// Write writes the key/value pairs of a flushed memTable.
 func (ssTable *SSTable) Write() error {
-	if len(ssTable.keyValuePairs) == 0 {
-		return errors.New("ssTable does not contain any key value pairs to write to " + ssTable.store.file.Name())
-	}
-	beginOffsetByKey, offset, err := ssTable.writeKeyValues()
-	if err != nil {
-		return err
-	}
-	indexBlock := NewIndexBlock(ssTable.store)
-	if err := indexBlock.Write(beginOffsetByKey, offset, ssTable.keyValuePairs); err != nil {
-		return err
-	}
-	if err := ssTable.store.Sync(); err != nil {
-		return errors.New("error while syncing the ssTable file " + ssTable.store.file.Name())
+	writer := newSSTableWriter(ssTable)
+	for _, keyValuePair := range ssTable.keyValuePairs {
+		if err := writer.add(keyValuePair); err != nil {
+			return err
+		}
 	}
-	return nil
+	return writer.finish()
 }
 
+// Get reads the only data block which may contain the key, the index is read only if it is not resident.
 func (ssTable *SSTable) Get(key model.Slice, keyComparator comparator.KeyComparator) model.GetResult {
-	indexBlock := NewIndexBlock(ssTable.store)
-	keyOffset, err := indexBlock.GetKeyOffset(key, keyComparator)
+	index, err := ssTable.index()
 	if err != nil {
 		return model.GetResult{Key: key, Exists: false}
 	}
-	if keyOffset == -1 {
+	blockIndex := index.Search(key, keyComparator)
+	if blockIndex == index.Len() {
 		return model.GetResult{Key: key, Exists: false}
 	}
-	_, resultValue, err := ssTable.readAt(keyOffset)
+	dataBlock, err := ssTable.dataBlock(index.Entry(blockIndex).Handle, ReadOptions{})
 	if err != nil {
 		return model.GetResult{Key: key, Exists: false}
 	}
-	return model.GetResult{Key: key, Value: resultValue.GetSlice(), Exists: true}
+	keyValuePair, ok := dataBlock.Get(key, keyComparator)
+	if !ok {
+		return model.GetResult{Key: key, Exists: false}
+	}
+	return model.GetResult{Key: key, Value: keyValuePair.Value, Exists: true, ExpiresAt: keyValuePair.ExpiresAt}
 }
 
-func (ssTable *SSTable) readAt(offset int64) (PersistentSSTableSlice, PersistentSSTableSlice, error) {
-	bytes := make([]byte, int(reservedTotalSize))
-	_, err := ssTable.store.ReadAt(bytes, offset)
+// MultiGet expects the keys sorted by the keyComparator and returns a result for every key in the same order,
+// a data block is read once for all the keys that fall in it.
+func (ssTable *SSTable) MultiGet(sortedKeys []model.Slice, keyComparator comparator.KeyComparator) []model.GetResult {
+	getResults := make([]model.GetResult, len(sortedKeys))
+	for index, key := range sortedKeys {
+		getResults[index] = model.GetResult{Key: key, Value: model.NilSlice(), Exists: false}
+	}
+	index, err := ssTable.index()
 	if err != nil {
-		return EmptyPersistentSSTableSlice(), EmptyPersistentSSTableSlice(), err
+		return getResults
+	}
+	dataBlockIndex := -1
+	var dataBlock *DataBlock
+	for keyIndex, key := range sortedKeys {
+		blockIndex := index.Search(key, keyComparator)
+		if blockIndex == index.Len() {
+			break
+		}
+		if dataBlockIndex != blockIndex {
+			if dataBlock, err = ssTable.dataBlock(index.Entry(blockIndex).Handle, ReadOptions{}); err != nil {
+				return getResults
+			}
+			dataBlockIndex = blockIndex
+		}
+		if keyValuePair, ok := dataBlock.Get(key, keyComparator); ok {
+			getResults[keyIndex] = model.GetResult{Key: key, Value: keyValuePair.Value, Exists: true, ExpiresAt: keyValuePair.ExpiresAt}
+		}
+	}
+	return getResults
+}
+
+// MayContainKey answers false if the key lies outside the key range of the ssTable, without reading the bloom filter or the index.
+func (ssTable *SSTable) MayContainKey(key model.Slice, keyComparator comparator.KeyComparator) bool {
+	return keyComparator.Compare(key, ssTable.smallestKey) >= 0 && keyComparator.Compare(key, ssTable.largestKey) <= 0
+}
+
+// Overlaps answers true if the key range of the ssTable overlaps [lowerBound, upperBound), an empty bound leaves that side open.
+func (ssTable *SSTable) Overlaps(lowerBound, upperBound model.Slice, keyComparator comparator.KeyComparator) bool {
+	if lowerBound.Size() > 0 && keyComparator.Compare(ssTable.largestKey, lowerBound) < 0 {
+		return false
+	}
+	if upperBound.Size() > 0 && keyComparator.Compare(ssTable.smallestKey, upperBound) >= 0 {
+		return false
 	}
-	sizeToRead := ActualTotalSize(bytes)
-	contents := make([]byte, sizeToRead)
+	return true
+}
 
-	_, err = ssTable.store.ReadAt(contents, offset)
+// overlapsKeyRange answers true if the key range of the ssTable overlaps [smallestKey, largestKey].
+func (ssTable *SSTable) overlapsKeyRange(smallestKey, largestKey model.Slice, keyComparator comparator.KeyComparator) bool {
+	return keyComparator.Compare(ssTable.largestKey, smallestKey) >= 0 && keyComparator.Compare(ssTable.smallestKey, largestKey) <= 0
+}
+
+// MayContainPrefix answers true unless the bloom filter proves that no key in the ssTable starts with the prefix,
+// which is possible only if the prefix extractor extracts a prefix from the scanned prefix itself.
+func (ssTable *SSTable) MayContainPrefix(prefix model.Slice) bool {
+	if ssTable.prefixExtractor == nil {
+		return true
+	}
+	extractedPrefix, ok := ssTable.prefixExtractor.Extract(prefix)
+	if !ok {
+		return true
+	}
+	return ssTable.bloomFilter.Has(extractedPrefix)
+}
+
+// CreatedAt is the age of the newest data in the ssTable once it is written.
+func (ssTable *SSTable) CreatedAt() time.Time {
+	return ssTable.createdAt
+}
+
+// IsEmpty answers true if there is no key/value pair to write, which happens only for an empty memTable.
+func (ssTable *SSTable) IsEmpty() bool {
+	return len(ssTable.keyValuePairs) == 0
+}
+
+// Size is the size of the ssTable file once it is written.
+func (ssTable *SSTable) Size() int64 {
+	return ssTable.size
+}
+
+func (ssTable *SSTable) acquire() {
+	atomic.AddInt32(&ssTable.references, 1)
+}
+
+// release deletes the ssTable file and its bloom filter once the last reference is released,
+// which happens only after the ssTable has been replaced in SSTables. The ssTable holds its own reference on the bloom filter,
+// so the bloom filter is unmapped only after the ssTable is deleted.
+func (ssTable *SSTable) release() {
+	if atomic.AddInt32(&ssTable.references, -1) == 0 {
+		ssTable.delete()
+	}
+}
+
+func (ssTable *SSTable) referenceCount() int32 {
+	return atomic.LoadInt32(&ssTable.references)
+}
+
+func (ssTable *SSTable) delete() {
+	ssTable.indexCache.Remove(strconv.Itoa(ssTable.fileId))
+	if err := ssTable.store.Remove(); err != nil {
+		log.Default().Println("Error while deleting the ssTable file " + ssTable.store.file.Name())
+	}
+	if err := ssTable.bloomFilters.Remove(ssTable.bloomFilter); err != nil {
+		log.Default().Println(err)
+	}
+	ssTable.bloomFilter.Release()
+}
+
+// Properties reads the properties written along with the ssTable.
+func (ssTable *SSTable) Properties() (Properties, error) {
+	return NewPropertiesBlock(ssTable.store).Read()
+}
+
+func (ssTable *SSTable) NewIterator(keyComparator comparator.KeyComparator, readOptions ReadOptions) iterator.Iterator {
+	return newSSTableIterator(ssTable, keyComparator, readOptions)
+}
+
+// index returns the resident index of the ssTable, the footer and the index block are read again only if the index was evicted.
+func (ssTable *SSTable) index() (*Index, error) {
+	cacheKey := strconv.Itoa(ssTable.fileId)
+	if index, ok := ssTable.indexCache.Get(cacheKey); ok {
+		return index.(*Index), nil
+	}
+	index, err := NewIndexBlock(ssTable.store).Read()
+	if err != nil {
+		return nil, err
+	}
+	ssTable.indexCache.Put(cacheKey, index, index.Size())
+	return index, nil
+}
+
+// dataBlock looks up the block cache by the file id and the block offset before reading the data block from the file.
+func (ssTable *SSTable) dataBlock(blockHandle BlockHandle, readOptions ReadOptions) (*DataBlock, error) {
+	cacheKey := strconv.Itoa(ssTable.fileId) + ":" + strconv.FormatInt(blockHandle.Offset, 10)
+	if dataBlock, ok := ssTable.blockCache.Get(cacheKey); ok {
+		return dataBlock.(*DataBlock), nil
+	}
+	dataBlock, err := ReadDataBlock(ssTable.store, blockHandle)
 	if err != nil {
-		return EmptyPersistentSSTableSlice(), EmptyPersistentSSTableSlice(), err
+		return nil, err
 	}
-	key, value := NewPersistentSSTableSliceKeyValuePair(contents)
-	return key, value, nil
+	if !readOptions.SkipFillingCache {
+		ssTable.blockCache.Put(cacheKey, dataBlock, dataBlock.Size())
+	}
+	return dataBlock, nil
 }
 
-func (ssTable *SSTable) writeKeyValues() ([]int64, int64, error) {
-	var offset int64 = 0
-	beginOffsetByKey := make([]int64, len(ssTable.keyValuePairs))
+func (ssTable *SSTable) putPrefixInBloomFilter(key model.Slice) error {
+	if ssTable.prefixExtractor == nil {
+		return nil
+	}
+	if prefix, ok := ssTable.prefixExtractor.Extract(key); ok {
+		return ssTable.bloomFilter.Put(prefix)
+	}
+	return nil
+}
 
-	for index, keyValuePair := range ssTable.keyValuePairs {
-		if bytesWritten, err := ssTable.store.WriteAt(NewPersistentSSTableSlice(keyValuePair).GetPersistentContents(), offset); err != nil {
-			return nil, 0, err
-		} else {
-			beginOffsetByKey[index] = offset
-			offset = offset + int64(bytesWritten)
-		}
-		if err := ssTable.bloomFilter.Put(keyValuePair.Key); err != nil {
-			return nil, 0, err
+// expiredAsTombstones writes a tombstone in place of every expired pair of a flushed memTable, an older ssTable may still hold
+// an older version of the key which the tombstone has to shadow till a compaction drops both of them.
+func expiredAsTombstones(keyValuePairs []model.KeyValuePair, now time.Time) []model.KeyValuePair {
+	for index, keyValuePair := range keyValuePairs {
+		if keyValuePair.IsExpiredAt(now) {
+			keyValuePairs[index] = keyValuePair.AsTombstone()
 		}
 	}
-	return beginOffsetByKey, offset, nil
+	return keyValuePairs
 }
 
 func createBloomFilter(fileNamePrefix int, totalKeys int, bloomFilters *filter.BloomFilters) (*filter.BloomFilter, error) {
@@ -0,0 +1,139 @@
+package sst
+
+import (
+	"sort"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+)
+
+// SSTableIterator takes the index of the ssTable on the first seek, binary searches it to seek and reads one data block at a time,
+// moving past either end of a block loads the adjacent block. A read error leaves the iterator invalid.
+// The iterator references the ssTable till it is closed.
+type SSTableIterator struct {
+	ssTable       *SSTable
+	keyComparator comparator.KeyComparator
+	readOptions   ReadOptions
+	index         *Index
+	indexLoaded   bool
+	blockIndex    int
+	keyValuePairs []model.KeyValuePair
+	position      int
+}
+
+func newSSTableIterator(ssTable *SSTable, keyComparator comparator.KeyComparator, readOptions ReadOptions) *SSTableIterator {
+	ssTable.acquire()
+	return &SSTableIterator{
+		ssTable:       ssTable,
+		keyComparator: keyComparator,
+		readOptions:   readOptions,
+	}
+}
+
+func (ssTableIterator *SSTableIterator) SeekToFirst() {
+	if ssTableIterator.loadIndex() {
+		ssTableIterator.loadBlock(0)
+		ssTableIterator.position = 0
+	}
+}
+
+func (ssTableIterator *SSTableIterator) SeekToLast() {
+	if ssTableIterator.loadIndex() {
+		ssTableIterator.loadBlock(ssTableIterator.index.Len() - 1)
+		ssTableIterator.position = len(ssTableIterator.keyValuePairs) - 1
+	}
+}
+
+func (ssTableIterator *SSTableIterator) Seek(key model.Slice) {
+	if ssTableIterator.loadIndex() {
+		ssTableIterator.loadBlock(ssTableIterator.index.Search(key, ssTableIterator.keyComparator))
+		ssTableIterator.position = sort.Search(len(ssTableIterator.keyValuePairs), func(index int) bool {
+			return ssTableIterator.keyComparator.Compare(ssTableIterator.keyValuePairs[index].Key, key) >= 0
+		})
+	}
+}
+
+func (ssTableIterator *SSTableIterator) SeekForPrev(key model.Slice) {
+	if !ssTableIterator.loadIndex() {
+		return
+	}
+	blockIndex := ssTableIterator.index.Search(key, ssTableIterator.keyComparator)
+	if blockIndex == ssTableIterator.index.Len() {
+		ssTableIterator.SeekToLast()
+		return
+	}
+	ssTableIterator.loadBlock(blockIndex)
+	ssTableIterator.position = sort.Search(len(ssTableIterator.keyValuePairs), func(index int) bool {
+		return ssTableIterator.keyComparator.Compare(ssTableIterator.keyValuePairs[index].Key, key) > 0
+	}) - 1
+	if ssTableIterator.position < 0 {
+		ssTableIterator.loadBlock(blockIndex - 1)
+		ssTableIterator.position = len(ssTableIterator.keyValuePairs) - 1
+	}
+}
+
+func (ssTableIterator *SSTableIterator) Next() {
+	ssTableIterator.position = ssTableIterator.position + 1
+	if ssTableIterator.position >= len(ssTableIterator.keyValuePairs) {
+		ssTableIterator.loadBlock(ssTableIterator.blockIndex + 1)
+		ssTableIterator.position = 0
+	}
+}
+
+func (ssTableIterator *SSTableIterator) Prev() {
+	ssTableIterator.position = ssTableIterator.position - 1
+	if ssTableIterator.position < 0 {
+		ssTableIterator.loadBlock(ssTableIterator.blockIndex - 1)
+		ssTableIterator.position = len(ssTableIterator.keyValuePairs) - 1
+	}
+}
+
+func (ssTableIterator *SSTableIterator) Valid() bool {
+	return ssTableIterator.position >= 0 && ssTableIterator.position < len(ssTableIterator.keyValuePairs)
+}
+
+func (ssTableIterator *SSTableIterator) Key() model.Slice {
+	return ssTableIterator.keyValuePairs[ssTableIterator.position].Key
+}
+
+func (ssTableIterator *SSTableIterator) Value() model.Slice {
+	return ssTableIterator.keyValuePairs[ssTableIterator.position].Value
+}
+
+func (ssTableIterator *SSTableIterator) ExpiresAt() int64 {
+	return ssTableIterator.keyValuePairs[ssTableIterator.position].ExpiresAt
+}
+
+func (ssTableIterator *SSTableIterator) Close() {
+	if ssTableIterator.ssTable != nil {
+		ssTableIterator.ssTable.release()
+		ssTableIterator.ssTable = nil
+	}
+	ssTableIterator.index = nil
+	ssTableIterator.keyValuePairs = nil
+	ssTableIterator.position = 0
+}
+
+func (ssTableIterator *SSTableIterator) loadIndex() bool {
+	if ssTableIterator.indexLoaded {
+		return true
+	}
+	index, err := ssTableIterator.ssTable.index()
+	if err != nil {
+		ssTableIterator.keyValuePairs = nil
+		return false
+	}
+	ssTableIterator.index, ssTableIterator.indexLoaded = index, true
+	return true
+}
+
+func (ssTableIterator *SSTableIterator) loadBlock(blockIndex int) {
+	ssTableIterator.blockIndex, ssTableIterator.keyValuePairs = blockIndex, nil
+	if blockIndex < 0 || blockIndex >= ssTableIterator.index.Len() {
+		return
+	}
+	dataBlock, err := ssTableIterator.ssTable.dataBlock(ssTableIterator.index.Entry(blockIndex).Handle, ssTableIterator.readOptions)
+	if err != nil {
+		return
+	}
+	ssTableIterator.keyValuePairs = dataBlock.KeyValuePairs()
+}
@@ -0,0 +1,163 @@
+package sst
+
+import (
+	"os"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"storage-engine-workshop/storage/memory"
+	"strconv"
+	"testing"
+)
+
+func TestIteratesOverAllKeysOfSSTableInOrder(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	for count := 10; count < 30; count++ {
+		memTable.Put(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+	}
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+
+	ssTableIterator := ssTable.NewIterator(comparator.StringKeyComparator{}, ReadOptions{})
+	defer ssTableIterator.Close()
+
+	count := 10
+	for ssTableIterator.SeekToFirst(); ssTableIterator.Valid(); ssTableIterator.Next() {
+		if ssTableIterator.Key().AsString() != "Key-"+strconv.Itoa(count) {
+			t.Fatalf("Expected key to be %v, received %v", "Key-"+strconv.Itoa(count), ssTableIterator.Key().AsString())
+		}
+		if ssTableIterator.Value().AsString() != "Value-"+strconv.Itoa(count) {
+			t.Fatalf("Expected value to be %v, received %v", "Value-"+strconv.Itoa(count), ssTableIterator.Value().AsString())
+		}
+		count = count + 1
+	}
+	if count != 30 {
+		t.Fatalf("Expected to iterate till %v, iterated till %v", 30, count)
+	}
+}
+
+func TestSeeksToAKeyInSSTable(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTable.Put(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk")))
+	memTable.Put(model.NewSlice([]byte("SDD")), model.NewSlice([]byte("Solid state")))
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+
+	ssTableIterator := ssTable.NewIterator(comparator.StringKeyComparator{}, ReadOptions{})
+	defer ssTableIterator.Close()
+
+	ssTableIterator.Seek(model.NewSlice([]byte("I")))
+	if !ssTableIterator.Valid() || ssTableIterator.Value().AsString() != "Solid state" {
+		t.Fatalf("Expected seek to position at value %v", "Solid state")
+	}
+	ssTableIterator.Next()
+	if ssTableIterator.Valid() {
+		t.Fatalf("Expected iterator to be exhausted but was valid at %v", ssTableIterator.Key().AsString())
+	}
+}
+
+func TestIteratesOverAllKeysOfSSTableInReverseOrder(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	for count := 10; count < 30; count++ {
+		memTable.Put(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+	}
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+
+	ssTableIterator := ssTable.NewIterator(comparator.StringKeyComparator{}, ReadOptions{})
+	defer ssTableIterator.Close()
+
+	count := 29
+	for ssTableIterator.SeekToLast(); ssTableIterator.Valid(); ssTableIterator.Prev() {
+		if ssTableIterator.Value().AsString() != "Value-"+strconv.Itoa(count) {
+			t.Fatalf("Expected value to be %v, received %v", "Value-"+strconv.Itoa(count), ssTableIterator.Value().AsString())
+		}
+		count = count - 1
+	}
+	if count != 9 {
+		t.Fatalf("Expected to iterate till %v, iterated till %v", 9, count)
+	}
+}
+
+func TestSeeksForPrevToAKeyInSSTable(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTable.Put(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk")))
+	memTable.Put(model.NewSlice([]byte("SDD")), model.NewSlice([]byte("Solid state")))
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+
+	ssTableIterator := ssTable.NewIterator(comparator.StringKeyComparator{}, ReadOptions{})
+	defer ssTableIterator.Close()
+
+	ssTableIterator.SeekForPrev(model.NewSlice([]byte("I")))
+	if !ssTableIterator.Valid() || ssTableIterator.Value().AsString() != "Hard disk" {
+		t.Fatalf("Expected seek for prev to position at value %v", "Hard disk")
+	}
+	ssTableIterator.Prev()
+	if ssTableIterator.Valid() {
+		t.Fatalf("Expected iterator to be exhausted but was valid at %v", ssTableIterator.Key().AsString())
+	}
+}
+
+func TestIteratesAcrossDataBlocksOfSSTableInBothDirections(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	for count := 10; count < 60; count++ {
+		memTable.Put(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+	}
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{BlockSize: 128})
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+
+	ssTableIterator := ssTable.NewIterator(comparator.StringKeyComparator{}, ReadOptions{})
+	defer ssTableIterator.Close()
+
+	count := 10
+	for ssTableIterator.SeekToFirst(); ssTableIterator.Valid(); ssTableIterator.Next() {
+		if ssTableIterator.Key().AsString() != "Key-"+strconv.Itoa(count) {
+			t.Fatalf("Expected key to be %v, received %v", "Key-"+strconv.Itoa(count), ssTableIterator.Key().AsString())
+		}
+		count = count + 1
+	}
+	if count != 60 {
+		t.Fatalf("Expected to iterate till %v, iterated till %v", 60, count)
+	}
+
+	count = 59
+	for ssTableIterator.SeekToLast(); ssTableIterator.Valid(); ssTableIterator.Prev() {
+		if ssTableIterator.Key().AsString() != "Key-"+strconv.Itoa(count) {
+			t.Fatalf("Expected key to be %v, received %v", "Key-"+strconv.Itoa(count), ssTableIterator.Key().AsString())
+		}
+		count = count - 1
+	}
+	if count != 9 {
+		t.Fatalf("Expected to iterate till %v, iterated till %v", 9, count)
+	}
+
+	ssTableIterator.SeekForPrev(model.NewSlice([]byte("Key-35-Missing")))
+	if !ssTableIterator.Valid() || ssTableIterator.Key().AsString() != "Key-35" {
+		t.Fatalf("Expected seek for prev to position at %v", "Key-35")
+	}
+}
@@ -0,0 +1,76 @@
+package sst
+
+import (
+	"storage-engine-workshop/storage/compression"
+	"storage-engine-workshop/storage/extractor"
+)
+
+const (
+	defaultBlockSize          = 4 * 1024
+	defaultRestartInterval    = 16
+	defaultIndexCacheCapacity = 8 * 1024 * 1024
+	defaultBlockCacheCapacity = 32 * 1024 * 1024
+)
+
+// SSTableOptions configures SSTables, zero values leave the defaults in place.
+// IndexCacheCapacity is the memory budget in bytes for the index blocks kept resident across all the ssTables
+// and BlockCacheCapacity is the same for the data blocks cached across all the ssTables.
+// RestartInterval is the number of key/value pairs in a data block between two keys stored in full,
+// Compression is the codec which compresses the data blocks written from now on
+// Compaction decides which ssTables are merged together, size-tiered compaction by default,
+// CompactionFilter, if set, decides the key/value pairs written to flushed and merged ssTables
+// and RateLimitBytesPerSecond, if positive, limits the rate at which all the ssTables are written.
+type SSTableOptions struct {
+	PrefixExtractor         extractor.PrefixExtractor
+	BlockSize               int
+	RestartInterval         int
+	Compression             compression.Codec
+	Compaction              CompactionStyle
+	CompactionFilter        CompactionFilter
+	RateLimitBytesPerSecond int64
+	IndexCacheCapacity      int64
+	BlockCacheCapacity      int64
+	rateLimiter             *RateLimiter
+}
+
+func (options SSTableOptions) blockSizeOrDefault() int {
+	if options.BlockSize <= 0 {
+		return defaultBlockSize
+	}
+	return options.BlockSize
+}
+
+func (options SSTableOptions) restartIntervalOrDefault() int {
+	if options.RestartInterval <= 0 {
+		return defaultRestartInterval
+	}
+	return options.RestartInterval
+}
+
+func (options SSTableOptions) compressionOrDefault() compression.Codec {
+	if options.Compression == nil {
+		return compression.NoCodec
+	}
+	return options.Compression
+}
+
+func (options SSTableOptions) compactionOrDefault() CompactionStyle {
+	if options.Compaction == nil {
+		return SizeTieredCompactionOptions{}
+	}
+	return options.Compaction
+}
+
+func (options SSTableOptions) indexCacheCapacityOrDefault() int64 {
+	if options.IndexCacheCapacity <= 0 {
+		return defaultIndexCacheCapacity
+	}
+	return options.IndexCacheCapacity
+}
+
+func (options SSTableOptions) blockCacheCapacityOrDefault() int64 {
+	if options.BlockCacheCapacity <= 0 {
+		return defaultBlockCacheCapacity
+	}
+	return options.BlockCacheCapacity
+}
@@ -0,0 +1,122 @@
+package sst
+
+import (
+	"errors"
+	"storage-engine-workshop/db/model"
+	"time"
+)
+
+// ssTableWriter writes the key/value pairs of an ssTable as they are added in the order of keys, a data block is written as soon as it fills up,
+// so that a compaction streams the merged pairs into its outputs without holding them in memory.
+// The index, the properties and the footer are written once the writer finishes.
+type ssTableWriter struct {
+	ssTable          *SSTable
+	dataBlockBuilder *DataBlockBuilder
+	indexEntries     []IndexEntry
+	offset           int64
+	totalEntries     uint64
+	rawKeySize       uint64
+	rawValueSize     uint64
+}
+
+func newSSTableWriter(ssTable *SSTable) *ssTableWriter {
+	return &ssTableWriter{
+		ssTable:          ssTable,
+		dataBlockBuilder: NewDataBlockBuilder(ssTable.restartInterval),
+	}
+}
+
+// add expects the keyValuePair to sort after every key/value pair added before it.
+func (writer *ssTableWriter) add(keyValuePair model.KeyValuePair) error {
+	ssTable := writer.ssTable
+	if writer.totalEntries == 0 {
+		ssTable.smallestKey = keyValuePair.Key
+	}
+	ssTable.largestKey = keyValuePair.Key
+	writer.dataBlockBuilder.Add(keyValuePair)
+	if err := ssTable.bloomFilter.Put(keyValuePair.Key); err != nil {
+		return err
+	}
+	if err := ssTable.putPrefixInBloomFilter(keyValuePair.Key); err != nil {
+		return err
+	}
+	writer.totalEntries = writer.totalEntries + 1
+	writer.rawKeySize = writer.rawKeySize + uint64(keyValuePair.Key.Size())
+	writer.rawValueSize = writer.rawValueSize + uint64(keyValuePair.Value.Size())
+	if writer.dataBlockBuilder.Size() >= ssTable.blockSize {
+		return writer.finishDataBlock()
+	}
+	return nil
+}
+
+// rawSize is the raw key and value size of the key/value pairs added so far.
+func (writer *ssTableWriter) rawSize() int64 {
+	return int64(writer.rawKeySize + writer.rawValueSize)
+}
+
+func (writer *ssTableWriter) finish() error {
+	ssTable := writer.ssTable
+	if writer.totalEntries == 0 {
+		return errors.New("ssTable does not contain any key value pairs to write to " + ssTable.store.file.Name())
+	}
+	if !writer.dataBlockBuilder.IsEmpty() {
+		if err := writer.finishDataBlock(); err != nil {
+			return err
+		}
+	}
+	//The way ssTable is laid out is: Data block 0 | ... | Data block N | Index block | Properties block | Footer
+	indexHandle, err := NewIndexBlock(ssTable.store).Write(writer.indexEntries, writer.offset)
+	if err != nil {
+		return err
+	}
+	if ssTable.createdAt.IsZero() {
+		ssTable.createdAt = time.Now()
+	}
+	propertiesHandle, err := NewPropertiesBlock(ssTable.store).Write(writer.properties(ssTable.createdAt), indexHandle.Offset+indexHandle.Size)
+	if err != nil {
+		return err
+	}
+	footer := Footer{IndexHandle: indexHandle, PropertiesHandle: propertiesHandle, FormatVersion: currentFormatVersion}
+	footerOffset := propertiesHandle.Offset + propertiesHandle.Size
+	if err := footer.Write(ssTable.store, footerOffset); err != nil {
+		return err
+	}
+	ssTable.size = footerOffset + footerSize
+	if err := ssTable.store.Sync(); err != nil {
+		return errors.New("error while syncing the ssTable file " + ssTable.store.file.Name())
+	}
+	return nil
+}
+
+func (writer *ssTableWriter) finishDataBlock() error {
+	contents, err := compressDataBlock(writer.dataBlockBuilder.Finish(), writer.ssTable.compression)
+	if err != nil {
+		return err
+	}
+	bytesWritten, err := writer.ssTable.store.WriteAt(contents, writer.offset)
+	if err != nil {
+		return err
+	}
+	writer.indexEntries = append(writer.indexEntries, IndexEntry{
+		LastKey: writer.dataBlockBuilder.LastKey(),
+		Handle:  BlockHandle{Offset: writer.offset, Size: int64(bytesWritten)},
+	})
+	writer.offset = writer.offset + int64(bytesWritten)
+	writer.dataBlockBuilder.Reset()
+	return nil
+}
+
+func (writer *ssTableWriter) properties(createdAt time.Time) Properties {
+	ssTable := writer.ssTable
+	return Properties{
+		TotalEntries:     writer.totalEntries,
+		RawKeySize:       writer.rawKeySize,
+		RawValueSize:     writer.rawValueSize,
+		SmallestKey:      ssTable.smallestKey,
+		LargestKey:       ssTable.largestKey,
+		SmallestSequence: ssTable.smallestSequence,
+		LargestSequence:  ssTable.largestSequence,
+		CreatedAt:        createdAt,
+		ComparatorName:   ssTable.comparatorName,
+	}
+}
@@ -4,24 +4,37 @@ import (
 	"errors"
 	"os"
 	"path"
+	"sort"
 	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/cache"
 	"storage-engine-workshop/storage/comparator"
 	"storage-engine-workshop/storage/filter"
+	"storage-engine-workshop/storage/iterator"
 	"storage-engine-workshop/storage/memory"
 	"sync"
 )
 
-const subDirectoryPermission = 0744
+const (
+	subDirectoryPermission = 0744
+	blockCacheShards       = 16
+)
 
+// SSTables holds the searchable ssTables in levels, along with the file ids of the ssTables that are being flushed and are not searchable yet.
+// Flushed ssTables are published in level 0 ordered from the oldest to the newest, compaction decides the ssTables of the deeper levels.
 type SSTables struct {
-	directory    string
-	nextFileId   int
-	tables       []*SSTable
-	bloomFilters *filter.BloomFilters
-	lock         sync.RWMutex
+	directory      string
+	nextFileId     int
+	levels         [][]*SSTable
+	pendingFileIds map[int]bool
+	bloomFilters   *filter.BloomFilters
+	indexCache     *cache.LRUCache
+	blockCache     *cache.ShardedLRUCache
+	options        SSTableOptions
+	lock           sync.RWMutex
+	compactionLock chan struct{}
 }
 
-func NewSSTables(directory string) (*SSTables, error) {
+func NewSSTables(directory string, options SSTableOptions) (*SSTables, error) {
 	if len(directory) == 0 {
 		return nil, errors.New("directory can not be empty while creating SSTables")
 	}
@@ -31,58 +44,151 @@ func NewSSTables(directory string) (*SSTables, error) {
 			return nil, err
 		}
 	}
+	options.rateLimiter = NewRateLimiter(options.RateLimitBytesPerSecond)
 	bloomFilters, err := filter.NewBloomFilters(directory, 0.001)
 	if err != nil {
 		return nil, err
 	}
 	return &SSTables{
-		directory:    subDirectory,
-		bloomFilters: bloomFilters,
-		nextFileId:   1,
+		directory:      subDirectory,
+		bloomFilters:   bloomFilters,
+		indexCache:     cache.NewLRUCache(options.indexCacheCapacityOrDefault()),
+		blockCache:     cache.NewShardedLRUCache(options.blockCacheCapacityOrDefault(), blockCacheShards),
+		options:        options,
+		nextFileId:     1,
+		levels:         make([][]*SSTable, 1),
+		pendingFileIds: make(map[int]bool),
+		compactionLock: make(chan struct{}, 1),
 	}, nil
 }
 
 func (ssTables *SSTables) NewSSTable(memTable *memory.MemTable) (*SSTable, error) {
-	ssTables.lock.Lock()
-	defer ssTables.lock.Unlock()
-
-	ssTable, err := NewSSTableFrom(memTable, ssTables.bloomFilters, ssTables.directory, ssTables.nextFileId)
+	fileId := ssTables.ReserveFileId()
+	ssTable, err := ssTables.NewReservedSSTable(memTable, fileId)
 	if err != nil {
+		ssTables.ReleaseFileId(fileId)
 		return nil, err
 	}
-	ssTables.nextFileId = ssTables.nextFileId + 1
 	return ssTable, nil
 }
 
+// ReserveFileId takes the file id of the ssTable of a memTable which is about to be flushed, the file id orders the ssTable
+// as the memTable was written and keeps compaction away from the older ssTables till the ssTable is allowed for search or discarded.
+func (ssTables *SSTables) ReserveFileId() int {
+	ssTables.lock.Lock()
+	defer ssTables.lock.Unlock()
+
+	fileId := ssTables.nextFileId
+	ssTables.pendingFileIds[fileId] = true
+	ssTables.nextFileId = ssTables.nextFileId + 1
+	return fileId
+}
+
+// NewReservedSSTable creates the ssTable of the memTable with a reserved file id, without holding the lock,
+// since it sorts the memTable, consults the compaction filter for every key and creates the files of the ssTable.
+func (ssTables *SSTables) NewReservedSSTable(memTable *memory.MemTable, fileId int) (*SSTable, error) {
+	return NewSSTableFrom(memTable, ssTables.bloomFilters, ssTables.indexCache, ssTables.blockCache, ssTables.directory, fileId, ssTables.options)
+}
+
+// ReleaseFileId gives up a reserved file id whose ssTable could not be created.
+func (ssTables *SSTables) ReleaseFileId(fileId int) {
+	ssTables.lock.Lock()
+	defer ssTables.lock.Unlock()
+
+	delete(ssTables.pendingFileIds, fileId)
+}
+
+// AllowSearchIn keeps the level 0 tables ordered by their generation, a memTable flushed earlier may finish writing its ssTable later
+// and must still be shadowed by the ssTables of newer memTables. The index of the ssTable is loaded before it is published.
 func (ssTables *SSTables) AllowSearchIn(ssTable *SSTable) {
+	_, _ = ssTable.index()
+	ssTable.acquire()
+
 	ssTables.lock.Lock()
 	defer ssTables.lock.Unlock()
 
-	ssTables.tables = append(ssTables.tables, ssTable)
+	delete(ssTables.pendingFileIds, ssTable.fileId)
+	level0 := ssTables.levels[0]
+	index := sort.Search(len(level0), func(index int) bool {
+		return level0[index].generation > ssTable.generation
+	})
+	tables := make([]*SSTable, 0, len(level0)+1)
+	tables = append(tables, level0[:index]...)
+	tables = append(tables, ssTable)
+	ssTables.levels[0] = append(tables, level0[index:]...)
 }
 
-func (ssTables *SSTables) Get(key model.Slice, keyComparator comparator.KeyComparator) model.GetResult {
+// Discard deletes the files of an ssTable that could not be written, the ssTable is never allowed for search.
+func (ssTables *SSTables) Discard(ssTable *SSTable) {
+	ssTables.lock.Lock()
+	delete(ssTables.pendingFileIds, ssTable.fileId)
+	ssTables.lock.Unlock()
+
+	ssTable.delete()
+}
+
+// CompactionBacklog is the number of ssTables waiting for a compaction as measured by the compaction style.
+func (ssTables *SSTables) CompactionBacklog() int {
 	ssTables.lock.RLock()
 	defer ssTables.lock.RUnlock()
 
-	for index := len(ssTables.tables) - 1; index >= 0; index-- {
-		table := ssTables.tables[index]
-		if table.bloomFilter.Has(key) {
-			if getResult := table.Get(key, keyComparator); getResult.Exists {
-				return getResult
-			}
+	return ssTables.options.compactionOrDefault().backlog(ssTables.levels)
+}
+
+// Snapshot returns the ssTables that are searchable at this point, tables allowed for search later are not visible in the snapshot.
+// The snapshot references its ssTables till it is released.
+func (ssTables *SSTables) Snapshot() *Snapshot {
+	ssTables.lock.RLock()
+	defer ssTables.lock.RUnlock()
+
+	levels := make([][]*SSTable, len(ssTables.levels))
+	for level, tables := range ssTables.levels {
+		levels[level] = make([]*SSTable, len(tables))
+		for index, table := range tables {
+			table.acquire()
+			levels[level][index] = table
 		}
 	}
-	return model.GetResult{Exists: false}
+	return &Snapshot{levels: levels}
 }
 
-func (ssTables *SSTables) MultiGet(keys []model.Slice, keyComparator comparator.KeyComparator) model.MultiGetResult {
+// TotalLevel0SSTables is the number of ssTables in level 0, the ssTables which are flushed and not merged yet.
+func (ssTables *SSTables) TotalLevel0SSTables() int {
 	ssTables.lock.RLock()
 	defer ssTables.lock.RUnlock()
 
-	response := model.MultiGetResult{}
-	for _, key := range keys {
-		response.Add(ssTables.Get(key, keyComparator))
-	}
-	return response
+	return len(ssTables.levels[0])
+}
+
+func (ssTables *SSTables) BlockCacheStatistics() cache.Statistics {
+	return ssTables.blockCache.Statistics()
+}
+
+func (ssTables *SSTables) Get(key model.Slice, keyComparator comparator.KeyComparator) model.GetResult {
+	snapshot := ssTables.Snapshot()
+	defer snapshot.Release()
+
+	return snapshot.Get(key, keyComparator)
+}
+
+func (ssTables *SSTables) MultiGet(keys []model.Slice, keyComparator comparator.KeyComparator) model.MultiGetResult {
+	snapshot := ssTables.Snapshot()
+	defer snapshot.Release()
+
+	return snapshot.MultiGet(keys, keyComparator)
+}
+
+// NewIterators returns iterators which reference their ssTables till they are closed.
+func (ssTables *SSTables) NewIterators(keyComparator comparator.KeyComparator, readOptions ReadOptions) []iterator.Iterator {
+	snapshot := ssTables.Snapshot()
+	defer snapshot.Release()
+
+	return snapshot.NewIterators(keyComparator, readOptions)
+}
+
+func (ssTables *SSTables) NewPrefixIterators(prefix model.Slice, keyComparator comparator.KeyComparator, readOptions ReadOptions) []iterator.Iterator {
+	snapshot := ssTables.Snapshot()
+	defer snapshot.Release()
+
+	return snapshot.NewPrefixIterators(prefix, keyComparator, readOptions)
 }
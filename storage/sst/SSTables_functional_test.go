@@ -24,7 +24,7 @@ func TestCreatesSSTableWith500KeysAndPutsAllKeysInBloomFilter(t *testing.T) {
 		memTable.Put(keyUsing(count), valueUsing(count))
 	}
 
-	ssTables, _ := NewSSTables(directory)
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
 	ssTable, _ := ssTables.NewSSTable(memTable)
 	_ = ssTable.Write()
 
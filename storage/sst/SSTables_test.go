@@ -4,10 +4,16 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path"
 	"storage-engine-workshop/db/model"
 	"storage-engine-workshop/storage/comparator"
+	"storage-engine-workshop/storage/compression"
+	"storage-engine-workshop/storage/extractor"
 	"storage-engine-workshop/storage/memory"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 )
 
 func tempDirectory() string {
@@ -25,7 +31,7 @@ func TestWritesSSTableToDisk(t *testing.T) {
 	directory := tempDirectory()
 	defer os.RemoveAll(directory)
 
-	ssTables, _ := NewSSTables(directory)
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
 	ssTable, _ := ssTables.NewSSTable(memTable)
 	if err := ssTable.Write(); err != nil {
 		t.Fatalf("Expected no errors while dump sstable file but received an error: %v", err)
@@ -39,7 +45,7 @@ func TestWrites2SSTablesToDisk(t *testing.T) {
 	directory := tempDirectory()
 	defer os.RemoveAll(directory)
 
-	ssTables, _ := NewSSTables(directory)
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
 	ssTableA, _ := ssTables.NewSSTable(memTable)
 	ssTableB, _ := ssTables.NewSSTable(memTable)
 
@@ -59,7 +65,7 @@ func TestCreatesSSTableAndPutsKeysInBloomFilter(t *testing.T) {
 	directory := tempDirectory()
 	defer os.RemoveAll(directory)
 
-	ssTables, _ := NewSSTables(directory)
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
 	ssTable, _ := ssTables.NewSSTable(memTable)
 	_ = ssTable.Write()
 
@@ -79,7 +85,7 @@ func TestGetsFromSSTable(t *testing.T) {
 	directory := tempDirectory()
 	defer os.RemoveAll(directory)
 
-	ssTables, _ := NewSSTables(directory)
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
 	ssTable, _ := ssTables.NewSSTable(memTable)
 	_ = ssTable.Write()
 
@@ -98,7 +104,7 @@ func TestGetsFromSSTableContainingMultipleKeyValues(t *testing.T) {
 	directory := tempDirectory()
 	defer os.RemoveAll(directory)
 
-	ssTables, _ := NewSSTables(directory)
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
 	ssTable, _ := ssTables.NewSSTable(memTable)
 	_ = ssTable.Write()
 
@@ -117,7 +123,7 @@ func TestGetNonExistentKeyFromSSTableContainingMultipleKeyValues(t *testing.T) {
 	directory := tempDirectory()
 	defer os.RemoveAll(directory)
 
-	ssTables, _ := NewSSTables(directory)
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
 	ssTable, _ := ssTables.NewSSTable(memTable)
 	_ = ssTable.Write()
 
@@ -127,9 +133,53 @@ func TestGetNonExistentKeyFromSSTableContainingMultipleKeyValues(t *testing.T) {
 	}
 }
 
+func TestGetsExpiryFromSSTable(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	expiresAt := model.ExpiresAfter(time.Hour)
+	memTable.PutWithExpiry(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk")), expiresAt)
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+
+	getResult := ssTable.Get(model.NewSlice([]byte("HDD")), comparator.StringKeyComparator{})
+	if getResult.ExpiresAt != expiresAt {
+		t.Fatalf("Expected expiresAt to be %v, received %v", expiresAt, getResult.ExpiresAt)
+	}
+	if getResult.Value.AsString() != "Hard disk" {
+		t.Fatalf("Expected value to be %v, received %v", "Hard disk", getResult.Value.AsString())
+	}
+}
+
+func TestWritesATombstoneInPlaceOfAnExpiredKeyValueSoThatItShadowsAnOlderVersion(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	publishSSTableWith(ssTables, map[string]string{"SDD": "Solid state drive"})
+
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTable.Put(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk")))
+	memTable.PutWithExpiry(model.NewSlice([]byte("SDD")), model.NewSlice([]byte("Solid state")), time.Now().Add(-time.Second).UnixNano())
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+	ssTables.AllowSearchIn(ssTable)
+
+	getResult := ssTables.Get(model.NewSlice([]byte("SDD")), comparator.StringKeyComparator{})
+	if !getResult.Exists || !getResult.IsExpiredAt(time.Now()) || getResult.Value.Size() != 0 {
+		t.Fatalf("Expected a tombstone for the expired key %v, received %v", "SDD", getResult)
+	}
+	if getResult := ssTable.Get(model.NewSlice([]byte("HDD")), comparator.StringKeyComparator{}); !getResult.Exists {
+		t.Fatalf("Expected key %v to be present in SSTable, but was not", "HDD")
+	}
+}
+
 func TestMultiGetsFromSSTablesBasedOnBloomFilter(t *testing.T) {
 	directory := tempDirectory()
-	ssTables, _ := NewSSTables(directory)
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
 	defer os.RemoveAll(directory)
 
 	memTableA := memory.NewMemTable(10, comparator.StringKeyComparator{})
@@ -170,3 +220,481 @@ func TestMultiGetsFromSSTablesBasedOnBloomFilter(t *testing.T) {
 		}
 	}
 }
+
+func TestCreatesSSTableAndPutsExtractedPrefixesInBloomFilter(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTable.Put(model.NewSlice([]byte("tenant-a:disk:1")), model.NewSlice([]byte("Hard disk")))
+	memTable.Put(model.NewSlice([]byte("tenant-a:disk:2")), model.NewSlice([]byte("Solid state")))
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{PrefixExtractor: extractor.NewDelimiterPrefixExtractor(':')})
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+
+	if !ssTable.MayContainPrefix(model.NewSlice([]byte("tenant-a:"))) {
+		t.Fatalf("Expected SSTable to contain prefix %v but did not", "tenant-a:")
+	}
+	if !ssTable.MayContainPrefix(model.NewSlice([]byte("tenant-a:disk"))) {
+		t.Fatalf("Expected SSTable to contain prefix %v but did not", "tenant-a:disk")
+	}
+	if ssTable.MayContainPrefix(model.NewSlice([]byte("tenant-b:"))) {
+		t.Fatalf("Expected SSTable to not contain prefix %v but did", "tenant-b:")
+	}
+}
+
+func TestCreatesPrefixIteratorsOnlyForSSTablesThatMayContainThePrefix(t *testing.T) {
+	memTableA := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTableA.Put(model.NewSlice([]byte("tenant-a:disk:1")), model.NewSlice([]byte("Hard disk")))
+	memTableB := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTableB.Put(model.NewSlice([]byte("tenant-b:disk:1")), model.NewSlice([]byte("Solid state")))
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{PrefixExtractor: extractor.NewDelimiterPrefixExtractor(':')})
+	for _, memTable := range []*memory.MemTable{memTableA, memTableB} {
+		ssTable, _ := ssTables.NewSSTable(memTable)
+		_ = ssTable.Write()
+		ssTables.AllowSearchIn(ssTable)
+	}
+
+	iterators := ssTables.NewPrefixIterators(model.NewSlice([]byte("tenant-b:")), comparator.StringKeyComparator{}, ReadOptions{})
+	if len(iterators) != 1 {
+		t.Fatalf("Expected %v prefix iterator, received %v", 1, len(iterators))
+	}
+	iterators[0].SeekToFirst()
+	if iterators[0].Value().AsString() != "Solid state" {
+		t.Fatalf("Expected value to be %v, received %v", "Solid state", iterators[0].Value().AsString())
+	}
+}
+
+func TestMultiGetsFromSSTablesInTheOrderOfKeysWithAResultForEveryKey(t *testing.T) {
+	directory := tempDirectory()
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	defer os.RemoveAll(directory)
+
+	memTableA := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTableA.Put(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk")))
+	memTableA.Put(model.NewSlice([]byte("SDD")), model.NewSlice([]byte("Solid state")))
+
+	ssTableA, _ := ssTables.NewSSTable(memTableA)
+	_ = ssTableA.Write()
+	ssTables.AllowSearchIn(ssTableA)
+
+	memTableB := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTableB.Put(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk drive")))
+	memTableB.Put(model.NewSlice([]byte("NVMe")), model.NewSlice([]byte("Non volatile media")))
+
+	ssTableB, _ := ssTables.NewSSTable(memTableB)
+	_ = ssTableB.Write()
+	ssTables.AllowSearchIn(ssTableB)
+
+	keys := []model.Slice{
+		model.NewSlice([]byte("SDD")),
+		model.NewSlice([]byte("Unknown")),
+		model.NewSlice([]byte("NVMe")),
+		model.NewSlice([]byte("HDD")),
+	}
+	expected := []model.GetResult{
+		{Key: keys[0], Value: model.NewSlice([]byte("Solid state")), Exists: true},
+		{Key: keys[1], Value: model.NilSlice(), Exists: false},
+		{Key: keys[2], Value: model.NewSlice([]byte("Non volatile media")), Exists: true},
+		{Key: keys[3], Value: model.NewSlice([]byte("Hard disk drive")), Exists: true},
+	}
+
+	allGetResults := ssTables.MultiGet(keys, comparator.StringKeyComparator{}).Values
+	if len(allGetResults) != len(expected) {
+		t.Fatalf("Expected %v results, received %v", len(expected), len(allGetResults))
+	}
+	for index, e := range expected {
+		if e.Key.AsString() != allGetResults[index].Key.AsString() ||
+			e.Exists != allGetResults[index].Exists ||
+			e.Value.AsString() != allGetResults[index].Value.AsString() {
+			t.Fatalf("Expected %v, received %v", e, allGetResults[index])
+		}
+	}
+	if keys[0].AsString() != "SDD" || keys[3].AsString() != "HDD" {
+		t.Fatalf("Expected multiGet to not reorder the keys of the caller")
+	}
+}
+
+func TestSearchesSSTablesInTheOrderOfTheirCreationEvenIfAllowedOutOfOrder(t *testing.T) {
+	directory := tempDirectory()
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	defer os.RemoveAll(directory)
+
+	olderMemTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	olderMemTable.Put(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk")))
+	newerMemTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	newerMemTable.Put(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk drive")))
+
+	olderSSTable, _ := ssTables.NewSSTable(olderMemTable)
+	newerSSTable, _ := ssTables.NewSSTable(newerMemTable)
+	_ = olderSSTable.Write()
+	_ = newerSSTable.Write()
+
+	ssTables.AllowSearchIn(newerSSTable)
+	ssTables.AllowSearchIn(olderSSTable)
+
+	getResult := ssTables.Get(model.NewSlice([]byte("HDD")), comparator.StringKeyComparator{})
+	if getResult.Value.AsString() != "Hard disk drive" {
+		t.Fatalf("Expected value to be %v, received %v", "Hard disk drive", getResult.Value.AsString())
+	}
+}
+
+func TestWritesSSTableInDataBlocksWithASparseIndexAndGetsEveryKey(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	for count := 10; count < 60; count++ {
+		memTable.Put(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+	}
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{BlockSize: 128})
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+
+	index, err := NewIndexBlock(ssTable.store).Read()
+	if err != nil {
+		t.Fatalf("Expected no error while reading the index block, received %v", err)
+	}
+	if index.Len() <= 1 || index.Len() >= 50 {
+		t.Fatalf("Expected the index to hold an entry per data block, found %v entries for %v keys", index.Len(), 50)
+	}
+	if index.Entry(index.Len()-1).LastKey.AsString() != "Key-59" {
+		t.Fatalf("Expected the last index entry to hold %v, received %v", "Key-59", index.Entry(index.Len()-1).LastKey.AsString())
+	}
+	for count := 10; count < 60; count++ {
+		getResult := ssTable.Get(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), comparator.StringKeyComparator{})
+		if getResult.Value.AsString() != "Value-"+strconv.Itoa(count) {
+			t.Fatalf("Expected value to be %v, received %v", "Value-"+strconv.Itoa(count), getResult.Value.AsString())
+		}
+	}
+	if getResult := ssTable.Get(model.NewSlice([]byte("Key-99")), comparator.StringKeyComparator{}); getResult.Exists {
+		t.Fatalf("Expected key %v to be missing, but was present", "Key-99")
+	}
+}
+
+func TestMultiGetsKeysSpreadAcrossDataBlocksOfAnSSTable(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	for count := 10; count < 60; count++ {
+		memTable.Put(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+	}
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{BlockSize: 128})
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+
+	sortedKeys := []model.Slice{
+		model.NewSlice([]byte("Key-10")),
+		model.NewSlice([]byte("Key-11")),
+		model.NewSlice([]byte("Key-35")),
+		model.NewSlice([]byte("Key-35-Missing")),
+		model.NewSlice([]byte("Key-59")),
+		model.NewSlice([]byte("Key-99")),
+	}
+	expectedValues := []string{"Value-10", "Value-11", "Value-35", "", "Value-59", ""}
+
+	getResults := ssTable.MultiGet(sortedKeys, comparator.StringKeyComparator{})
+	for index, getResult := range getResults {
+		if getResult.Value.AsString() != expectedValues[index] {
+			t.Fatalf("Expected value to be %v, received %v", expectedValues[index], getResult.Value.AsString())
+		}
+	}
+}
+
+func TestKeepsTheIndexOfAPublishedSSTableResident(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTable.Put(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk")))
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+	ssTables.AllowSearchIn(ssTable)
+
+	if _, ok := ssTables.indexCache.Get(strconv.Itoa(ssTable.fileId)); !ok {
+		t.Fatalf("Expected the index of the published SSTable to be resident")
+	}
+}
+
+func TestGetsFromSSTablesWhoseIndexesAreEvictedUnderAMemoryBudget(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{BlockSize: 64, IndexCacheCapacity: 256})
+	for table := 0; table < 5; table++ {
+		memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+		for count := 0; count < 20; count++ {
+			key := "Key-" + strconv.Itoa(table) + "-" + strconv.Itoa(count)
+			memTable.Put(model.NewSlice([]byte(key)), model.NewSlice([]byte("Value-"+key)))
+		}
+		ssTable, _ := ssTables.NewSSTable(memTable)
+		_ = ssTable.Write()
+		ssTables.AllowSearchIn(ssTable)
+	}
+	if ssTables.indexCache.Usage() > 256 {
+		t.Fatalf("Expected resident indexes to fit in %v bytes, found %v bytes", 256, ssTables.indexCache.Usage())
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(4)
+	for reader := 0; reader < 4; reader++ {
+		go func() {
+			defer wg.Done()
+			for table := 0; table < 5; table++ {
+				for count := 0; count < 20; count++ {
+					key := "Key-" + strconv.Itoa(table) + "-" + strconv.Itoa(count)
+					if getResult := ssTables.Get(model.NewSlice([]byte(key)), comparator.StringKeyComparator{}); getResult.Value.AsString() != "Value-"+key {
+						t.Errorf("Expected value to be %v, received %v", "Value-"+key, getResult.Value.AsString())
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestServesARepeatedGetFromTheSharedBlockCache(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTable.Put(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk")))
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+	ssTables.AllowSearchIn(ssTable)
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if getResult := ssTables.Get(model.NewSlice([]byte("HDD")), comparator.StringKeyComparator{}); getResult.Value.AsString() != "Hard disk" {
+			t.Fatalf("Expected value to be %v, received %v", "Hard disk", getResult.Value.AsString())
+		}
+	}
+	statistics := ssTables.BlockCacheStatistics()
+	if statistics.Misses != 1 || statistics.Hits != 1 {
+		t.Fatalf("Expected 1 miss and 1 hit, received %v misses and %v hits", statistics.Misses, statistics.Hits)
+	}
+}
+
+func TestIteratesWithoutFillingTheBlockCacheIfTheReadSkipsFillingIt(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	for count := 10; count < 60; count++ {
+		memTable.Put(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte("Value-"+strconv.Itoa(count))))
+	}
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{BlockSize: 128})
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+	ssTables.AllowSearchIn(ssTable)
+
+	totalKeys := 0
+	iterator := ssTables.NewIterators(comparator.StringKeyComparator{}, ReadOptions{SkipFillingCache: true})[0]
+	for iterator.SeekToFirst(); iterator.Valid(); iterator.Next() {
+		totalKeys = totalKeys + 1
+	}
+	iterator.Close()
+
+	if totalKeys != 50 {
+		t.Fatalf("Expected %v keys, received %v", 50, totalKeys)
+	}
+	if ssTables.blockCache.Usage() != 0 {
+		t.Fatalf("Expected the block cache to be empty, found %v bytes", ssTables.blockCache.Usage())
+	}
+	iterator = ssTables.NewIterators(comparator.StringKeyComparator{}, ReadOptions{})[0]
+	for iterator.SeekToFirst(); iterator.Valid(); iterator.Next() {
+	}
+	iterator.Close()
+	if ssTables.blockCache.Usage() == 0 {
+		t.Fatalf("Expected the block cache to be filled by a read which does not skip filling it")
+	}
+}
+
+func TestGetsFromSSTablesWrittenWithDifferentCompressionCodecs(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{BlockSize: 256, Compression: compression.FlateCodec})
+	for table, codec := range []compression.Codec{compression.FlateCodec, compression.NoCodec} {
+		ssTables.options.Compression = codec
+		memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+		for count := 0; count < 40; count++ {
+			key := "Key-" + strconv.Itoa(table) + "-" + strconv.Itoa(count)
+			memTable.Put(model.NewSlice([]byte(key)), model.NewSlice([]byte(`{"kind": "document", "key": "`+key+`"}`)))
+		}
+		ssTable, _ := ssTables.NewSSTable(memTable)
+		_ = ssTable.Write()
+		ssTables.AllowSearchIn(ssTable)
+	}
+	for table := 0; table < 2; table++ {
+		for count := 0; count < 40; count++ {
+			key := "Key-" + strconv.Itoa(table) + "-" + strconv.Itoa(count)
+			expectedValue := `{"kind": "document", "key": "` + key + `"}`
+			if getResult := ssTables.Get(model.NewSlice([]byte(key)), comparator.StringKeyComparator{}); getResult.Value.AsString() != expectedValue {
+				t.Fatalf("Expected value to be %v, received %v", expectedValue, getResult.Value.AsString())
+			}
+		}
+	}
+}
+
+func TestChargesTheBlockCacheWithTheDecompressedSizeOfACompressedBlock(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{Compression: compression.FlateCodec})
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	for count := 0; count < 100; count++ {
+		memTable.Put(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte(`{"kind": "document", "tags": ["storage", "engine"]}`)))
+	}
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+	ssTables.AllowSearchIn(ssTable)
+
+	_ = ssTables.Get(model.NewSlice([]byte("Key-0")), comparator.StringKeyComparator{})
+	index, _ := ssTable.index()
+	blockHandle := index.Entry(0).Handle
+	dataBlock, _ := ReadDataBlock(ssTable.store, blockHandle)
+
+	if ssTables.blockCache.Usage() != dataBlock.Size() {
+		t.Fatalf("Expected the block cache usage to be %v bytes, received %v", dataBlock.Size(), ssTables.blockCache.Usage())
+	}
+	if dataBlock.Size() <= blockHandle.Size {
+		t.Fatalf("Expected the decompressed block of %v bytes to be larger than the compressed block of %v bytes", dataBlock.Size(), blockHandle.Size)
+	}
+}
+
+func TestCompressedSSTableIsSmallerThanAnUncompressedOne(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	fileSizeWith := func(codec compression.Codec) int64 {
+		ssTables, _ := NewSSTables(directory, SSTableOptions{Compression: codec})
+		memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+		for count := 0; count < 100; count++ {
+			memTable.Put(model.NewSlice([]byte("Key-"+strconv.Itoa(count))), model.NewSlice([]byte(`{"kind": "document", "tags": ["storage", "engine"]}`)))
+		}
+		ssTable, _ := ssTables.NewSSTable(memTable)
+		_ = ssTable.Write()
+		fileInfo, _ := ssTable.store.file.Stat()
+		return fileInfo.Size()
+	}
+	if compressedSize, uncompressedSize := fileSizeWith(compression.FlateCodec), fileSizeWith(compression.NoCodec); compressedSize >= uncompressedSize {
+		t.Fatalf("Expected the compressed ssTable to be smaller than %v bytes, received %v bytes", uncompressedSize, compressedSize)
+	}
+}
+
+func TestReadsThePropertiesOfAnSSTable(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTable.Put(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk")))
+	memTable.RecordSequence(3)
+	memTable.Put(model.NewSlice([]byte("SSD")), model.NewSlice([]byte("Solid state")))
+	memTable.RecordSequence(4)
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	beforeWrite := time.Now()
+	_ = ssTable.Write()
+
+	properties, err := ssTable.Properties()
+	if err != nil {
+		t.Fatalf("Expected no error while reading the properties, received %v", err)
+	}
+	if properties.TotalEntries != 2 || properties.RawKeySize != 6 || properties.RawValueSize != 20 {
+		t.Fatalf("Expected 2 entries with 6 key bytes and 20 value bytes, received %v entries with %v key bytes and %v value bytes", properties.TotalEntries, properties.RawKeySize, properties.RawValueSize)
+	}
+	if properties.SmallestKey.AsString() != "HDD" || properties.LargestKey.AsString() != "SSD" {
+		t.Fatalf("Expected key range [%v, %v], received [%v, %v]", "HDD", "SSD", properties.SmallestKey.AsString(), properties.LargestKey.AsString())
+	}
+	if properties.SmallestSequence != 3 || properties.LargestSequence != 4 {
+		t.Fatalf("Expected sequence range [%v, %v], received [%v, %v]", 3, 4, properties.SmallestSequence, properties.LargestSequence)
+	}
+	if properties.CreatedAt.Before(beforeWrite) {
+		t.Fatalf("Expected the creation time to be after %v, received %v", beforeWrite, properties.CreatedAt)
+	}
+	if properties.ComparatorName != "StringKeyComparator" {
+		t.Fatalf("Expected comparator name %v, received %v", "StringKeyComparator", properties.ComparatorName)
+	}
+}
+
+func TestRejectsATruncatedOrAForeignSSTableFile(t *testing.T) {
+	memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+	memTable.Put(model.NewSlice([]byte("HDD")), model.NewSlice([]byte("Hard disk")))
+
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	ssTable, _ := ssTables.NewSSTable(memTable)
+	_ = ssTable.Write()
+
+	size, _ := ssTable.store.Size()
+	_ = ssTable.store.file.Truncate(size - 1)
+	if _, err := ReadFooter(ssTable.store); err == nil {
+		t.Fatalf("Expected an error while reading the footer of a truncated ssTable file")
+	}
+
+	foreignStore, _ := NewStore(path.Join(directory, "foreign.sst"))
+	_, _ = foreignStore.WriteAt(make([]byte, 128), 0)
+	if _, err := ReadFooter(foreignStore); err == nil {
+		t.Fatalf("Expected an error while reading the footer of a foreign file")
+	}
+}
+
+func TestSkipsSSTablesWhoseKeyRangeCanNotContainTheKeys(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	for _, keys := range [][]string{{"a", "c"}, {"m", "p"}} {
+		memTable := memory.NewMemTable(10, comparator.StringKeyComparator{})
+		for _, key := range keys {
+			memTable.Put(model.NewSlice([]byte(key)), model.NewSlice([]byte("Value-"+key)))
+		}
+		ssTable, _ := ssTables.NewSSTable(memTable)
+		_ = ssTable.Write()
+		ssTables.AllowSearchIn(ssTable)
+	}
+	olderTable := ssTables.Snapshot().levels[0][0]
+	if !olderTable.MayContainKey(model.NewSlice([]byte("b")), comparator.StringKeyComparator{}) {
+		t.Fatalf("Expected key %v to be in the key range of the ssTable", "b")
+	}
+	if olderTable.MayContainKey(model.NewSlice([]byte("d")), comparator.StringKeyComparator{}) {
+		t.Fatalf("Expected key %v to be outside the key range of the ssTable", "d")
+	}
+	if getResult := ssTables.Get(model.NewSlice([]byte("p")), comparator.StringKeyComparator{}); getResult.Value.AsString() != "Value-p" {
+		t.Fatalf("Expected value to be %v, received %v", "Value-p", getResult.Value.AsString())
+	}
+	multiGetResult := ssTables.MultiGet([]model.Slice{model.NewSlice([]byte("c")), model.NewSlice([]byte("z")), model.NewSlice([]byte("m"))}, comparator.StringKeyComparator{})
+	for index, expectedValue := range []string{"Value-c", "", "Value-m"} {
+		if multiGetResult.Values[index].Value.AsString() != expectedValue {
+			t.Fatalf("Expected value to be %v, received %v", expectedValue, multiGetResult.Values[index].Value.AsString())
+		}
+	}
+
+	readOptions := ReadOptions{LowerBound: model.NewSlice([]byte("d")), UpperBound: model.NewSlice([]byte("n"))}
+	if iterators := ssTables.NewIterators(comparator.StringKeyComparator{}, readOptions); len(iterators) != 1 {
+		t.Fatalf("Expected %v iterator for the ssTables overlapping [d, n), received %v", 1, len(iterators))
+	}
+	readOptions = ReadOptions{LowerBound: model.NewSlice([]byte("c")), UpperBound: model.NewSlice([]byte("m"))}
+	if iterators := ssTables.NewIterators(comparator.StringKeyComparator{}, readOptions); len(iterators) != 1 {
+		t.Fatalf("Expected %v iterator for the ssTables overlapping [c, m), received %v", 1, len(iterators))
+	}
+	if iterators := ssTables.NewIterators(comparator.StringKeyComparator{}, ReadOptions{}); len(iterators) != 2 {
+		t.Fatalf("Expected %v iterators for an unbounded scan, received %v", 2, len(iterators))
+	}
+}
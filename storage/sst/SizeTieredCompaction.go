@@ -0,0 +1,98 @@
+package sst
+
+import (
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+)
+
+const (
+	defaultMinMergeWidth = 4
+	defaultMaxMergeWidth = 32
+	defaultSizeRatio     = 1.5
+)
+
+// SizeTieredCompactionOptions configure which ssTables are merged together, zero values leave the defaults in place.
+// A run of at least MinMergeWidth and at most MaxMergeWidth ssTables, adjacent in age, is merged
+// if the size of every ssTable in the run is within SizeRatio times the average size of the ssTables before it in the run.
+// Size-tiered compaction keeps every ssTable in level 0.
+type SizeTieredCompactionOptions struct {
+	MinMergeWidth int
+	MaxMergeWidth int
+	SizeRatio     float64
+}
+
+func (options SizeTieredCompactionOptions) minMergeWidthOrDefault() int {
+	if options.MinMergeWidth < 2 {
+		return defaultMinMergeWidth
+	}
+	return options.MinMergeWidth
+}
+
+func (options SizeTieredCompactionOptions) maxMergeWidthOrDefault() int {
+	if options.MaxMergeWidth < options.minMergeWidthOrDefault() {
+		return defaultMaxMergeWidth
+	}
+	return options.MaxMergeWidth
+}
+
+func (options SizeTieredCompactionOptions) sizeRatioOrDefault() float64 {
+	if options.SizeRatio <= 1 {
+		return defaultSizeRatio
+	}
+	return options.SizeRatio
+}
+
+func (options SizeTieredCompactionOptions) pick(levels [][]*SSTable, keyComparator comparator.KeyComparator) *compaction {
+	run := options.run(levels[0])
+	if len(run) == 0 {
+		return nil
+	}
+	return &compaction{inputs: newestFirst(run), outputLevel: 0}
+}
+
+// run returns the oldest run of similarly sized ssTables which are adjacent in age, tables are ordered from the oldest to the newest.
+// Merging only adjacent ssTables keeps the newest version of a key shadowing the older ones once the merged ssTable takes the place of the run.
+func (options SizeTieredCompactionOptions) run(tables []*SSTable) []*SSTable {
+	minMergeWidth, maxMergeWidth, sizeRatio := options.minMergeWidthOrDefault(), options.maxMergeWidthOrDefault(), options.sizeRatioOrDefault()
+	isSimilar := func(size int64, averageSize float64) bool {
+		return float64(size) <= averageSize*sizeRatio && float64(size) >= averageSize/sizeRatio
+	}
+	for begin := 0; begin+minMergeWidth <= len(tables); begin++ {
+		end, totalSize := begin+1, tables[begin].Size()
+		for end < len(tables) && end-begin < maxMergeWidth && isSimilar(tables[end].Size(), float64(totalSize)/float64(end-begin)) {
+			totalSize = totalSize + tables[end].Size()
+			end = end + 1
+		}
+		if end-begin >= minMergeWidth {
+			return tables[begin:end]
+		}
+	}
+	return nil
+}
+
+// backlog is the number of ssTables in the runs which are ready to be merged, the ssTables of a tier still filling up are not counted.
+func (options SizeTieredCompactionOptions) backlog(levels [][]*SSTable) int {
+	tables, total := levels[0], 0
+	for run := options.run(tables); len(run) > 0; run = options.run(tables) {
+		total = total + len(run)
+		for index, table := range tables {
+			if table == run[len(run)-1] {
+				tables = tables[index+1:]
+				break
+			}
+		}
+	}
+	return total
+}
+
+func newestFirst(tables []*SSTable) []*SSTable {
+	reversed := make([]*SSTable, 0, len(tables))
+	for index := len(tables) - 1; index >= 0; index-- {
+		reversed = append(reversed, tables[index])
+	}
+	return reversed
+}
+
+func (options SizeTieredCompactionOptions) pickRange(levels [][]*SSTable, level int, lowerBound, upperBound model.Slice, keyComparator comparator.KeyComparator) *compaction {
+	return level0RunOverlapping(levels, level, lowerBound, upperBound, keyComparator)
+}
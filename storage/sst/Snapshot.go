@@ -0,0 +1,135 @@
+package sst
+
+import (
+	"sort"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"storage-engine-workshop/storage/iterator"
+)
+
+// Snapshot is an immutable view of the levels of ssTables. Level 0 holds ssTables with overlapping key ranges ordered from the oldest to the newest,
+// every deeper level holds ssTables with disjoint key ranges ordered by key and older than the ssTables of the levels above it.
+type Snapshot struct {
+	levels [][]*SSTable
+}
+
+// Release releases the references the snapshot holds on its ssTables, the snapshot must not be used after it is released.
+func (snapshot *Snapshot) Release() {
+	for _, table := range snapshot.tables() {
+		table.release()
+	}
+	snapshot.levels = nil
+}
+
+// Get searches level 0 from the newest ssTable to the oldest, and then binary searches every deeper level
+// for the only ssTable whose key range may contain the key.
+func (snapshot *Snapshot) Get(key model.Slice, keyComparator comparator.KeyComparator) model.GetResult {
+	mayContain := func(table *SSTable) bool {
+		return table.MayContainKey(key, keyComparator) && table.bloomFilter.Has(key)
+	}
+	for level, tables := range snapshot.levels {
+		if level == 0 {
+			for index := len(tables) - 1; index >= 0; index-- {
+				if mayContain(tables[index]) {
+					if getResult := tables[index].Get(key, keyComparator); getResult.Exists {
+						return getResult
+					}
+				}
+			}
+			continue
+		}
+		index := sort.Search(len(tables), func(index int) bool {
+			return keyComparator.Compare(tables[index].largestKey, key) >= 0
+		})
+		if index < len(tables) && mayContain(tables[index]) {
+			if getResult := tables[index].Get(key, keyComparator); getResult.Exists {
+				return getResult
+			}
+		}
+	}
+	return model.GetResult{Exists: false}
+}
+
+// MultiGet returns a result for every key in the order of keys, it sorts a copy of keys once
+// and probes every ssTable, newest first, with the keys that are still missing and may be present as per its key range and its bloom filter.
+func (snapshot *Snapshot) MultiGet(keys []model.Slice, keyComparator comparator.KeyComparator) model.MultiGetResult {
+	getResults := make([]model.GetResult, len(keys))
+	missingIndices := make([]int, len(keys))
+	for index, key := range keys {
+		getResults[index] = model.GetResult{Key: key, Value: model.NilSlice(), Exists: false}
+		missingIndices[index] = index
+	}
+	sort.SliceStable(missingIndices, func(i, j int) bool {
+		return keyComparator.Compare(keys[missingIndices[i]], keys[missingIndices[j]]) < 0
+	})
+
+	for _, table := range snapshot.tables() {
+		if len(missingIndices) == 0 {
+			break
+		}
+		var candidateIndices []int
+		var candidateKeys []model.Slice
+		for _, keyIndex := range missingIndices {
+			if table.MayContainKey(keys[keyIndex], keyComparator) && table.bloomFilter.Has(keys[keyIndex]) {
+				candidateIndices = append(candidateIndices, keyIndex)
+				candidateKeys = append(candidateKeys, keys[keyIndex])
+			}
+		}
+		if len(candidateKeys) == 0 {
+			continue
+		}
+		found := make(map[int]bool)
+		for candidateIndex, getResult := range table.MultiGet(candidateKeys, keyComparator) {
+			if getResult.Exists {
+				getResults[candidateIndices[candidateIndex]] = getResult
+				found[candidateIndices[candidateIndex]] = true
+			}
+		}
+		var stillMissingIndices []int
+		for _, keyIndex := range missingIndices {
+			if !found[keyIndex] {
+				stillMissingIndices = append(stillMissingIndices, keyIndex)
+			}
+		}
+		missingIndices = stillMissingIndices
+	}
+	return model.MultiGetResult{Values: getResults}
+}
+
+// NewIterators skips the ssTables whose key range does not overlap the bounds of the readOptions.
+func (snapshot *Snapshot) NewIterators(keyComparator comparator.KeyComparator, readOptions ReadOptions) []iterator.Iterator {
+	var iterators []iterator.Iterator
+	for _, table := range snapshot.tables() {
+		if table.Overlaps(readOptions.LowerBound, readOptions.UpperBound, keyComparator) {
+			iterators = append(iterators, table.NewIterator(keyComparator, readOptions))
+		}
+	}
+	return iterators
+}
+
+// NewPrefixIterators skips the ssTables whose bloom filter can not contain the prefix or whose key range does not overlap the bounds,
+// without reading their index block.
+func (snapshot *Snapshot) NewPrefixIterators(prefix model.Slice, keyComparator comparator.KeyComparator, readOptions ReadOptions) []iterator.Iterator {
+	var iterators []iterator.Iterator
+	for _, table := range snapshot.tables() {
+		if table.Overlaps(readOptions.LowerBound, readOptions.UpperBound, keyComparator) && table.MayContainPrefix(prefix) {
+			iterators = append(iterators, table.NewIterator(keyComparator, readOptions))
+		}
+	}
+	return iterators
+}
+
+// tables returns the ssTables from the newest to the oldest, the ssTables of a level deeper than 0 are in key order.
+func (snapshot *Snapshot) tables() []*SSTable {
+	var tables []*SSTable
+	for level, levelTables := range snapshot.levels {
+		if level == 0 {
+			for index := len(levelTables) - 1; index >= 0; index-- {
+				tables = append(tables, levelTables[index])
+			}
+			continue
+		}
+		tables = append(tables, levelTables...)
+	}
+	return tables
+}
@@ -6,8 +6,10 @@ import (
 	"os"
 )
 
+// Store writes through the rate limiter of SSTables, if there is one.
 type Store struct {
-	file *os.File
+	file        *os.File
+	rateLimiter *RateLimiter
 }
 
 func NewStore(filePath string) (*Store, error) {
@@ -19,6 +21,7 @@ func NewStore(filePath string) (*Store, error) {
 }
 
 func (store *Store) WriteAt(bytes []byte, offset int64) (int, error) {
+	store.rateLimiter.Request(len(bytes))
 	bytesWritten, err := store.file.WriteAt(bytes, offset)
 	if err != nil {
 		return 0, err
@@ -51,3 +54,11 @@ func (store *Store) Size() (int64, error) {
 func (store *Store) Sync() error {
 	return store.file.Sync()
 }
+
+// Remove closes and deletes the file of the store.
+func (store *Store) Remove() error {
+	if err := store.file.Close(); err != nil {
+		return err
+	}
+	return os.Remove(store.file.Name())
+}
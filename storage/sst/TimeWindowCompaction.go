@@ -0,0 +1,79 @@
+package sst
+
+import (
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"time"
+)
+
+const (
+	defaultWindowSize              = time.Hour
+	defaultTimeWindowMinMergeWidth = 4
+)
+
+// TimeWindowCompactionOptions configure time-window compaction, zero values leave the defaults in place.
+// ssTables are bucketed by the window of WindowSize their creation time falls in and only ssTables of the same window are merged.
+// A window which has passed is merged into a single ssTable, the current window is merged once it has MinMergeWidth ssTables.
+// Time-window compaction suits time-series data where the ssTables of a window are rarely touched once the window has passed.
+type TimeWindowCompactionOptions struct {
+	WindowSize    time.Duration
+	MinMergeWidth int
+}
+
+func (options TimeWindowCompactionOptions) windowSizeOrDefault() time.Duration {
+	if options.WindowSize <= 0 {
+		return defaultWindowSize
+	}
+	return options.WindowSize
+}
+
+func (options TimeWindowCompactionOptions) minMergeWidthOrDefault() int {
+	if options.MinMergeWidth < 2 {
+		return defaultTimeWindowMinMergeWidth
+	}
+	return options.MinMergeWidth
+}
+
+func (options TimeWindowCompactionOptions) pick(levels [][]*SSTable, keyComparator comparator.KeyComparator) *compaction {
+	return options.pickAt(levels, time.Now())
+}
+
+// pickAt returns the oldest run of ssTables, adjacent in age, whose creation time falls in the same window.
+func (options TimeWindowCompactionOptions) pickAt(levels [][]*SSTable, now time.Time) *compaction {
+	tables, windowSize := levels[0], options.windowSizeOrDefault()
+	currentWindow := now.Truncate(windowSize)
+	for begin := 0; begin < len(tables); {
+		window, end := tables[begin].createdAt.Truncate(windowSize), begin+1
+		for end < len(tables) && tables[end].createdAt.Truncate(windowSize).Equal(window) {
+			end = end + 1
+		}
+		minMergeWidth := 2
+		if !window.Before(currentWindow) {
+			minMergeWidth = options.minMergeWidthOrDefault()
+		}
+		if end-begin >= minMergeWidth {
+			return &compaction{inputs: newestFirst(tables[begin:end]), outputLevel: 0}
+		}
+		begin = end
+	}
+	return nil
+}
+
+// backlog is the number of ssTables in the runs of a window which are ready to be merged.
+func (options TimeWindowCompactionOptions) backlog(levels [][]*SSTable) int {
+	tables, total, now := levels[0], 0, time.Now()
+	for compaction := options.pickAt([][]*SSTable{tables}, now); compaction != nil; compaction = options.pickAt([][]*SSTable{tables}, now) {
+		total = total + len(compaction.inputs)
+		for index, table := range tables {
+			if table == compaction.inputs[0] {
+				tables = tables[index+1:]
+				break
+			}
+		}
+	}
+	return total
+}
+
+func (options TimeWindowCompactionOptions) pickRange(levels [][]*SSTable, level int, lowerBound, upperBound model.Slice, keyComparator comparator.KeyComparator) *compaction {
+	return level0RunOverlapping(levels, level, lowerBound, upperBound, keyComparator)
+}
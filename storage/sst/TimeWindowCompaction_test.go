@@ -0,0 +1,61 @@
+package sst
+
+import (
+	"os"
+	"storage-engine-workshop/db/model"
+	"storage-engine-workshop/storage/comparator"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMergesOnlyTheSSTablesOfAPassedTimeWindow(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{})
+	now := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+	createdAt := []time.Time{
+		now.Add(-3 * time.Hour),
+		now.Add(-90 * time.Minute),
+		now.Add(-80 * time.Minute),
+		now.Add(-10 * time.Minute),
+		now.Add(-5 * time.Minute),
+	}
+	for table, at := range createdAt {
+		publishSSTableWith(ssTables, map[string]string{"Key-" + strconv.Itoa(table): "Value-" + strconv.Itoa(table)}).createdAt = at
+	}
+
+	compaction := TimeWindowCompactionOptions{WindowSize: time.Hour}.pickAt(ssTables.levels, now)
+	if compaction == nil || len(compaction.inputs) != 2 {
+		t.Fatalf("Expected the %v ssTables of the window 09:00 to be merged, received %v", 2, compaction)
+	}
+	for _, input := range compaction.inputs {
+		if !input.createdAt.Truncate(time.Hour).Equal(now.Add(-90 * time.Minute).Truncate(time.Hour)) {
+			t.Fatalf("Expected only the ssTables of the window 09:00 to be merged, received an ssTable created at %v", input.createdAt)
+		}
+	}
+}
+
+func TestMergesTheCurrentTimeWindowOnlyOnceItHasTheMinMergeWidth(t *testing.T) {
+	directory := tempDirectory()
+	defer os.RemoveAll(directory)
+
+	ssTables, _ := NewSSTables(directory, SSTableOptions{Compaction: TimeWindowCompactionOptions{WindowSize: 24 * time.Hour, MinMergeWidth: 3}})
+	for table := 1; table <= 2; table++ {
+		publishSSTableWith(ssTables, map[string]string{"Company": "TW-" + strconv.Itoa(table)})
+	}
+	if compacted, _ := ssTables.Compact(comparator.StringKeyComparator{}); compacted {
+		t.Fatalf("Expected %v ssTables of the current window not to be merged", 2)
+	}
+	publishSSTableWith(ssTables, map[string]string{"Company": "TW-3"})
+	if compacted, _ := ssTables.Compact(comparator.StringKeyComparator{}); !compacted {
+		t.Fatalf("Expected %v ssTables of the current window to be merged", 3)
+	}
+	if len(ssTables.levels[0]) != 1 {
+		t.Fatalf("Expected a single ssTable after compaction, received %v", len(ssTables.levels[0]))
+	}
+	if getResult := ssTables.Get(model.NewSlice([]byte("Company")), comparator.StringKeyComparator{}); getResult.Value.AsString() != "TW-3" {
+		t.Fatalf("Expected value to be %v, received %v", "TW-3", getResult.Value.AsString())
+	}
+}